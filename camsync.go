@@ -0,0 +1,129 @@
+package main
+
+import (
+	"path/filepath"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// Reference photo chooser for one camera
+type syncSide struct {
+	camera *widget.Select
+	photo  *widget.Select
+	img    *fyne.Container
+	photos []*Photo
+}
+
+func (l *PhotoList) newSyncSide(changed func()) *syncSide {
+	s := &syncSide{img: container.NewGridWrap(fyne.NewSize(240, 160))}
+	s.photo = widget.NewSelect(nil, func(string) {
+		s.img.RemoveAll()
+		if p := s.selected(); p != nil {
+			var img *canvas.Image
+			if p.Img != nil {
+				img = canvas.NewImageFromImage(p.Img.Image)
+				img.FillMode = canvas.ImageFillContain
+			} else {
+				img = p.img(8)
+			}
+			s.img.Add(img)
+		}
+		changed()
+	})
+	s.camera = widget.NewSelect(l.cameras(), func(camera string) {
+		s.photos = nil
+		names := []string{}
+		for _, p := range l.cameraPhotos(camera) {
+			if !p.Dates[p.DateChoice].IsZero() {
+				s.photos = append(s.photos, p)
+				names = append(names, filepath.Base(p.File))
+			}
+		}
+		s.photo.ClearSelected()
+		s.photo.Options = names
+		s.photo.Refresh()
+		changed()
+	})
+	return s
+}
+
+// selected reference photo or nil
+func (s *syncSide) selected() *Photo {
+	i := s.photo.SelectedIndex()
+	if i < 0 || i >= len(s.photos) {
+		return nil
+	}
+	return s.photos[i]
+}
+
+func (s *syncSide) form(title string) fyne.CanvasObject {
+	return container.NewVBox(
+		widget.NewLabelWithStyle(title, fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		widget.NewForm(
+			widget.NewFormItem("Camera", s.camera),
+			widget.NewFormItem("Photo", s.photo),
+		),
+		container.NewCenter(s.img),
+	)
+}
+
+// show camera clocks synchronisation dialog:
+// reference and target photos of the same moment give offset applied to all target camera photos
+func (l *PhotoList) syncCameras() {
+	changes := []DateChange{}
+	preview := newDateChangesPreview(&changes)
+	offsetLabel := widget.NewLabel("")
+
+	var ref, target *syncSide
+	update := func() {
+		changes = changes[:0]
+		offsetLabel.SetText("Choose reference and target photos of the same moment")
+		if ref == nil || target == nil {
+			return
+		}
+		rp, tp := ref.selected(), target.selected()
+		if rp == nil || tp == nil || ref.camera.Selected == target.camera.Selected {
+			preview.Refresh()
+			return
+		}
+		offset := rp.Dates[rp.DateChoice].Sub(tp.Dates[tp.DateChoice].Time)
+		offsetLabel.SetText("Target camera clock offset: " + formatOffset(offset))
+		for _, p := range l.cameraPhotos(target.camera.Selected) {
+			d := p.Dates[p.DateChoice]
+			if p.Droped || d.IsZero() {
+				continue
+			}
+			changes = append(changes, DateChange{Photo: p, Date: d.Add(offset)})
+		}
+		preview.Refresh()
+	}
+	ref = l.newSyncSide(update)
+	target = l.newSyncSide(update)
+	update()
+
+	top := container.NewVBox(
+		container.NewGridWithColumns(2, ref.form("Reference camera"), target.form("Camera to adjust")),
+		offsetLabel,
+	)
+	content := container.NewBorder(top, nil, nil, nil, preview)
+	dlg := dialog.NewCustomConfirm("Synchronise cameras", "Apply", "Cancel", content, func(ok bool) {
+		if ok {
+			l.applyDateChanges(changes)
+		}
+	}, wMain)
+	dlg.Resize(fyne.NewSize(800, 700))
+	dlg.Show()
+}
+
+// signed duration text
+func formatOffset(d time.Duration) string {
+	if d < 0 {
+		return "-" + (-d).String()
+	}
+	return "+" + d.String()
+}
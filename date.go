@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// EXIF date layouts
+const (
+	DateFormat   = "2006:01:02 15:04:05" // 24-hour EXIF date and time
+	SubSecFormat = ".000"
+	OffsetFormat = "-07:00"
+)
+
+// longest date text, used to size date widgets
+const DateTemplate = DateFormat + SubSecFormat + OffsetFormat
+
+// Photo date with optional sub-second precision and time zone offset.
+// Dates without offset keep wall clock in time.Local
+type Date struct {
+	time.Time
+	SubSec    bool // has fractional seconds
+	HasOffset bool // time zone offset is known
+}
+
+// parse EXIF date with optional fractional seconds and offset, e.g.
+// "2023:05:14 15:04:05", "2023:05:14 15:04:05.120", "2023:05:14 15:04:05+02:00"
+func parseDate(s string) (Date, error) {
+	s = strings.TrimSpace(s)
+	d := Date{SubSec: len(s) > len(DateFormat) && s[len(DateFormat)] == '.'}
+	var err error
+	d.Time, err = time.Parse(DateFormat+"Z07:00", s)
+	if err == nil {
+		d.HasOffset = true
+		return d, nil
+	}
+	d.Time, err = time.ParseInLocation(DateFormat, s, time.Local)
+	if err != nil {
+		return Date{}, fmt.Errorf("invalid date %q, expected format %s[.000][+hh:mm]", s, DateFormat)
+	}
+	return d, nil
+}
+
+// parse XMP (ISO 8601) date, e.g. "2023-05-14", "2023-05-14T15:04", "2023-05-14T15:04:05.12+02:00"
+func parseXMPDate(s string) (Date, error) {
+	s = strings.TrimSpace(s)
+	switch len(s) {
+	case len("2006-01-02"):
+		s += "T00:00:00"
+	case len("2006-01-02T15:04"):
+		s += ":00"
+	}
+	if len(s) < len(DateFormat) || s[4] != '-' || s[7] != '-' || s[10] != 'T' {
+		return Date{}, fmt.Errorf("invalid XMP date %q", s)
+	}
+	return parseDate(s[:4] + ":" + s[5:7] + ":" + s[8:10] + " " + s[11:])
+}
+
+// make date from time, keep sub-seconds and offset if subSec and hasOffset
+func newDate(t time.Time, subSec, hasOffset bool) Date {
+	if !subSec {
+		t = t.Truncate(time.Second)
+	}
+	return Date{Time: t, SubSec: subSec, HasOffset: hasOffset}
+}
+
+// date layout according to precision and offset
+func (d Date) layout(base string) string {
+	if d.SubSec {
+		base += SubSecFormat
+	}
+	if d.HasOffset {
+		base += OffsetFormat
+	}
+	return base
+}
+
+// EXIF formatted date, empty for zero date
+func (d Date) String() string {
+	if d.IsZero() {
+		return ""
+	}
+	return d.Format(d.layout(DateFormat))
+}
+
+// ISO 8601 formatted date for XMP
+func (d Date) XMPString() string {
+	return d.Format(d.layout("2006-01-02T15:04:05"))
+}
+
+// date shifted by duration keeping precision and offset
+func (d Date) Add(dur time.Duration) Date {
+	d.Time = d.Time.Add(dur)
+	return d
+}
+
+// date shifted by years, months and days keeping precision and offset
+func (d Date) AddDate(years, months, days int) Date {
+	d.Time = d.Time.AddDate(years, months, days)
+	return d
+}
+
+// date with the same wall clock in zone loc, in system zone without offset if loc is nil
+func (d Date) WallIn(loc *time.Location) Date {
+	if d.IsZero() {
+		return d
+	}
+	d.HasOffset = loc != nil
+	if loc == nil {
+		loc = time.Local
+	}
+	d.Time = time.Date(d.Year(), d.Month(), d.Day(), d.Hour(), d.Minute(), d.Second(), d.Nanosecond(), loc)
+	return d
+}
+
+// date at the same instant in zone loc, in system zone without offset if loc is nil
+func (d Date) In(loc *time.Location) Date {
+	if d.IsZero() {
+		return d
+	}
+	d.HasOffset = loc != nil
+	if loc == nil {
+		loc = time.Local
+	}
+	d.Time = d.Time.In(loc)
+	return d
+}
+
+// true if d is before e, zero dates go last
+func (d Date) Before(e Date) bool {
+	if d.IsZero() || e.IsZero() {
+		return !d.IsZero() && e.IsZero()
+	}
+	return d.Time.Before(e.Time)
+}
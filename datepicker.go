@@ -0,0 +1,187 @@
+package main
+
+import (
+	"strconv"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// Date and time picker: validated entry with calendar popup and field spinners
+type DatePicker struct {
+	widget.BaseWidget
+	OnChanged func(d Date, valid bool)
+
+	date     Date
+	entry    *widget.Entry
+	button   *widget.Button
+	popUp    *widget.PopUp
+	month    *widget.Label
+	calendar *fyne.Container
+	fields   []*widget.Label
+}
+
+func newDatePicker(d Date, changed func(d Date, valid bool)) *DatePicker {
+	p := &DatePicker{date: d}
+	p.ExtendBaseWidget(p)
+	p.entry = widget.NewEntry()
+	p.entry.SetPlaceHolder(DateFormat)
+	p.entry.Validator = func(s string) error {
+		_, err := parseDate(s)
+		return err
+	}
+	p.entry.SetText(d.String())
+	p.entry.OnChanged = p.entryChanged
+	p.button = widget.NewButtonWithIcon("", theme.HistoryIcon(), p.showPopUp)
+	p.OnChanged = changed
+	return p
+}
+
+func (p *DatePicker) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(container.NewBorder(nil, nil, nil, p.button, p.entry))
+}
+
+// set picker date without calling OnChanged
+func (p *DatePicker) SetDate(d Date) {
+	p.date = d
+	changed := p.OnChanged
+	p.OnChanged = nil
+	p.entry.SetText(d.String())
+	p.OnChanged = changed
+}
+
+// entered text
+func (p *DatePicker) Text() string {
+	return p.entry.Text
+}
+
+// set entered text and validate it
+func (p *DatePicker) SetText(s string) {
+	p.entry.SetText(s)
+	p.entry.Validate()
+}
+
+// true if entered text is a valid date
+func (p *DatePicker) Valid() bool {
+	return p.entry.Validate() == nil
+}
+
+func (p *DatePicker) Enable() {
+	p.entry.Enable()
+	p.button.Enable()
+}
+
+func (p *DatePicker) Disable() {
+	p.entry.Disable()
+	p.button.Disable()
+}
+
+func (p *DatePicker) entryChanged(s string) {
+	d, err := parseDate(s)
+	if err == nil {
+		p.date = d
+	}
+	if p.OnChanged != nil {
+		p.OnChanged(p.date, err == nil)
+	}
+}
+
+// set date from calendar or spinners
+func (p *DatePicker) pick(d Date) {
+	p.date = d
+	p.entry.SetText(d.String())
+	p.refreshPopUp()
+}
+
+// date to start picking from
+func (p *DatePicker) current() Date {
+	if p.date.IsZero() {
+		return newDate(time.Now(), false, false)
+	}
+	return p.date
+}
+
+// show calendar and time spinners popup below the picker
+func (p *DatePicker) showPopUp() {
+	c := fyne.CurrentApp().Driver().CanvasForObject(p)
+	if c == nil {
+		return
+	}
+	p.month = widget.NewLabelWithStyle("", fyne.TextAlignCenter, fyne.TextStyle{Bold: true})
+	prevMonth := widget.NewButtonWithIcon("", theme.NavigateBackIcon(), func() { p.pick(p.current().AddDate(0, -1, 0)) })
+	nextMonth := widget.NewButtonWithIcon("", theme.NavigateNextIcon(), func() { p.pick(p.current().AddDate(0, 1, 0)) })
+	p.calendar = container.NewGridWithColumns(7)
+
+	spinners := container.NewHBox()
+	p.fields = nil
+	for _, f := range []struct {
+		name string
+		step func(d Date, n int) Date
+	}{
+		{"Year", func(d Date, n int) Date { return d.AddDate(n, 0, 0) }},
+		{"Month", func(d Date, n int) Date { return d.AddDate(0, n, 0) }},
+		{"Day", func(d Date, n int) Date { return d.AddDate(0, 0, n) }},
+		{"Hour", func(d Date, n int) Date { return d.Add(time.Duration(n) * time.Hour) }},
+		{"Min", func(d Date, n int) Date { return d.Add(time.Duration(n) * time.Minute) }},
+		{"Sec", func(d Date, n int) Date { return d.Add(time.Duration(n) * time.Second) }},
+	} {
+		step := f.step
+		value := widget.NewLabelWithStyle("", fyne.TextAlignCenter, fyne.TextStyle{Monospace: true})
+		p.fields = append(p.fields, value)
+		spinners.Add(container.NewVBox(
+			widget.NewLabelWithStyle(f.name, fyne.TextAlignCenter, fyne.TextStyle{}),
+			widget.NewButtonWithIcon("", theme.MoveUpIcon(), func() { p.pick(step(p.current(), 1)) }),
+			value,
+			widget.NewButtonWithIcon("", theme.MoveDownIcon(), func() { p.pick(step(p.current(), -1)) }),
+		))
+	}
+
+	content := container.NewVBox(
+		container.NewBorder(nil, nil, prevMonth, nextMonth, p.month),
+		p.calendar,
+		widget.NewSeparator(),
+		spinners,
+		container.NewHBox(layout.NewSpacer(), widget.NewButton("Close", func() { p.popUp.Hide() })),
+	)
+	p.popUp = widget.NewPopUp(content, c)
+	p.refreshPopUp()
+	pos := fyne.CurrentApp().Driver().AbsolutePositionForObject(p)
+	p.popUp.ShowAtPosition(pos.Add(fyne.NewPos(0, p.Size().Height)))
+}
+
+// fill calendar month grid and spinner values for current date
+func (p *DatePicker) refreshPopUp() {
+	if p.popUp == nil {
+		return
+	}
+	d := p.current()
+	p.month.SetText(d.Format("January 2006"))
+
+	p.calendar.RemoveAll()
+	for _, wd := range []string{"Mo", "Tu", "We", "Th", "Fr", "Sa", "Su"} {
+		p.calendar.Add(widget.NewLabelWithStyle(wd, fyne.TextAlignCenter, fyne.TextStyle{Bold: true}))
+	}
+	first := time.Date(d.Year(), d.Month(), 1, 0, 0, 0, 0, d.Location())
+	for i := 0; i < (int(first.Weekday())+6)%7; i++ {
+		p.calendar.Add(layout.NewSpacer())
+	}
+	days := first.AddDate(0, 1, -1).Day()
+	for day := 1; day <= days; day++ {
+		delta := day - d.Day()
+		btn := widget.NewButton(strconv.Itoa(day), func() { p.pick(p.current().AddDate(0, 0, delta)) })
+		if delta == 0 {
+			btn.Importance = widget.HighImportance
+		}
+		p.calendar.Add(btn)
+	}
+	p.calendar.Refresh()
+
+	values := []int{d.Year(), int(d.Month()), d.Day(), d.Hour(), d.Minute(), d.Second()}
+	for i, v := range values {
+		p.fields[i].SetText(strconv.Itoa(v))
+	}
+}
@@ -2,11 +2,15 @@ package main
 
 import (
 	"bufio"
+	"bytes"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/tajtiattila/metadata/exif"
+	"github.com/tajtiattila/metadata/exif/exiftag"
 )
 
 // get EXIF metadata from file
@@ -24,54 +28,180 @@ func getJpegExif(fileName string) (*exif.Exif, error) {
 	return fileExif, nil
 }
 
-// get EXIF date from file
-func getExifDate(file string) string {
-	fileExif, err := getJpegExif(file)
-	if err != nil {
-		return ""
+// EXIF date sources in order of preference
+const (
+	SourceOriginal  = "Original"
+	SourceDigitized = "Digitized"
+	SourceDateTime  = "DateTime"
+	SourceGPS       = "GPS"
+)
+
+// get EXIF capture date and the tag it was taken from
+func getExifDate(fileExif *exif.Exif) (date Date, source string) {
+	if fileExif == nil {
+		return Date{}, ""
+	}
+	if d, ok := exifDate(fileExif, exiftag.DateTimeOriginal, exiftag.SubSecTimeOriginal, OffsetTimeOriginal); ok {
+		return d, SourceOriginal
+	}
+	if d, ok := exifDate(fileExif, exiftag.DateTimeDigitized, exiftag.SubSecTimeDigitized, OffsetTimeDigitized); ok {
+		return d, SourceDigitized
+	}
+	if d, ok := exifDate(fileExif, exiftag.DateTime, exiftag.SubSecTime, OffsetTime); ok {
+		return d, SourceDateTime
 	}
-	exifTime, ok := fileExif.DateTime()
+	if t, ok := gpsDateTime(fileExif); ok {
+		return newDate(t.Local(), t.Nanosecond() != 0, false), SourceGPS
+	}
+	return Date{}, ""
+}
+
+// get date from EXIF date, sub-second and offset tags
+func exifDate(x *exif.Exif, timeTag, subSecTag, offsetTag uint32) (Date, bool) {
+	t, _, ok := x.Time(timeTag, subSecTag)
 	if !ok {
+		return Date{}, false
+	}
+	_, hasSubSec := x.Tag(subSecTag).Ascii()
+	d := newDate(t, hasSubSec, false)
+	if off, ok := x.Tag(offsetTag).Ascii(); ok {
+		if zt, err := time.Parse(OffsetFormat, strings.TrimSpace(off)); err == nil {
+			d.Time = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), d.Nanosecond(), zt.Location())
+			d.HasOffset = true
+		}
+	}
+	return d, true
+}
+
+// get camera make and model
+func getCamera(fileExif *exif.Exif) string {
+	if fileExif == nil {
+		return ""
+	}
+	maker := exifString(fileExif, exiftag.Make)
+	model := exifString(fileExif, exiftag.Model)
+	if maker == "" || strings.HasPrefix(strings.ToLower(model), strings.ToLower(maker)) {
+		return model
+	}
+	return strings.TrimSpace(maker + " " + model)
+}
+
+// get camera body serial number
+func getCameraSerial(fileExif *exif.Exif) string {
+	if fileExif == nil {
 		return ""
 	}
-	return exifTime.Format(DateFormat)
+	return exifString(fileExif, BodySerialNumber)
 }
 
+// get trimmed ASCII tag value
+func exifString(x *exif.Exif, tag uint32) string {
+	s, _ := x.Tag(tag).Ascii()
+	return strings.Trim(s, " \x00")
+}
+
+// get GPS fix time (UTC) from GPSDateStamp and GPSTimeStamp tags
+func gpsDateTime(x *exif.Exif) (time.Time, bool) {
+	ds, ok := x.Tag(exiftag.GPSDateStamp).Ascii()
+	if !ok {
+		return time.Time{}, false
+	}
+	d, err := time.Parse("2006:01:02", ds)
+	if err != nil {
+		return time.Time{}, false
+	}
+	ts := x.Tag(exiftag.GPSTimeStamp).Rational()
+	if len(ts) != 6 || ts[1] == 0 || ts[3] == 0 || ts[5] == 0 {
+		return time.Time{}, false
+	}
+	secs := float64(ts[0])/float64(ts[1])*3600 + float64(ts[2])/float64(ts[3])*60 + float64(ts[4])/float64(ts[5])
+	return d.Add(time.Duration(secs * float64(time.Second))), true
+}
+
+// EXIF/XMP date tags to write on save
+const (
+	WriteDateTime = 1 << iota
+	WriteDateTimeOriginal
+	WriteDateTimeDigitized
+	WriteXMPDateCreated
+)
+
+const DefaultDateTags = WriteDateTime | WriteDateTimeOriginal | WriteDateTimeDigitized
+
+// EXIF 2.3 tags missing in exiftag
+const (
+	OffsetTime          = exiftag.Exif | 0x9010
+	OffsetTimeOriginal  = exiftag.Exif | 0x9011
+	OffsetTimeDigitized = exiftag.Exif | 0x9012
+	BodySerialNumber    = exiftag.Exif | 0xa431
+)
+
 // update EXIF dates in file
-func updateExifDate(file, backupDirName, date string) error {
-	newDate, err := time.Parse(DateFormat, date)
+func updateExifDate(file, backupDirName string, date Date, tags int) error {
+	// rewrite date values in place when possible, otherwise re-encode the whole EXIF
+	patched, err := patchExifDate(file, backupDirName, date, tags)
+	if patched || err != nil {
+		return err
+	}
+	metadata, err := getJpegExif(file)
 	if err != nil {
 		return err
 	}
+	setExifDate(metadata, date, tags)
+
 	src := file
 	bak := filepath.Join(backupDirName, filepath.Base(file))
 	err = os.Rename(src, bak)
 	if err != nil {
 		return err
 	}
-	metadata, err := getJpegExif(bak)
+	data, err := os.ReadFile(bak)
 	if err != nil {
 		return err
 	}
-
-	metadata.SetDateTime(newDate)
-
-	f, err := os.Open(bak)
+	var b bytes.Buffer
+	err = exif.Copy(&b, bytes.NewReader(data), metadata)
 	if err != nil {
 		return err
 	}
-	defer f.Close()
-	reader := bufio.NewReader(f)
-	of, err := os.Create(src)
-	if err != nil {
-		return err
+	out := b.Bytes()
+	if tags&WriteXMPDateCreated != 0 {
+		out, err = updateJpegXMP(out, func(packet string) string {
+			return xmpSet(packet, xmpDateCreated, date.XMPString())
+		})
+		if err != nil {
+			return err
+		}
 	}
-	writer := bufio.NewWriter(of)
-	defer of.Close()
-	err = exif.Copy(writer, reader, metadata)
-	if err != nil {
-		return err
+	return os.WriteFile(src, out, 0664)
+}
+
+// set date tags selected by tags mask with matching sub-second and offset tags,
+// offset tags are kept as is when date offset is unknown
+func setExifDate(x *exif.Exif, d Date, tags int) {
+	v := exif.Ascii(d.Format(DateFormat))
+	var subv exif.Value
+	if d.SubSec {
+		sub := strings.TrimRight(fmt.Sprintf("%09d", d.Nanosecond()), "0")
+		if sub == "" {
+			sub = "0"
+		}
+		subv = exif.Ascii(sub)
+	}
+	set := func(dateTag, subSecTag, offsetTag uint32) {
+		x.Set(dateTag, v)
+		x.Set(subSecTag, subv)
+		if d.HasOffset {
+			x.Set(offsetTag, exif.Ascii(d.Format(OffsetFormat)))
+		}
+	}
+	if tags&WriteDateTime != 0 {
+		set(exiftag.DateTime, exiftag.SubSecTime, OffsetTime)
+	}
+	if tags&WriteDateTimeOriginal != 0 {
+		set(exiftag.DateTimeOriginal, exiftag.SubSecTimeOriginal, OffsetTimeOriginal)
+	}
+	if tags&WriteDateTimeDigitized != 0 {
+		set(exiftag.DateTimeDigitized, exiftag.SubSecTimeDigitized, OffsetTimeDigitized)
 	}
-	writer.Flush()
-	return nil
 }
@@ -0,0 +1,312 @@
+package main
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/tajtiattila/metadata/exif"
+)
+
+var exifPrefix = []byte("Exif\x00\x00")
+
+var errExifTooLong = errors.New("EXIF data is too long for JPEG segment")
+
+// IFD pointer tags
+const (
+	tagExifIFD    = 0x8769
+	tagGPSIFD     = 0x8825
+	tagMakerNote  = 0x927c
+	tagThumbOfs   = 0x0201
+	tagThumbBytes = 0x0202
+)
+
+// TIFF IFD entry, pos is offset of the entry in TIFF data or -1 for a new one
+type tiffEntry struct {
+	Tag   uint16
+	Type  uint16
+	Count uint32
+	Value [4]byte // inline value or offset of external value
+	data  []byte  // value of new entry
+	pos   int
+}
+
+// in place editor of TIFF data of EXIF APP1 segment, entries and values not edited keep their bytes and offsets
+type tiffEditor struct {
+	bo   binary.ByteOrder
+	tiff []byte
+}
+
+// editor of EXIF segment with marker and length
+func newTiffEditor(seg []byte) (*tiffEditor, error) {
+	if len(seg) < 4+len(exifPrefix)+8 {
+		return nil, exif.ErrCorruptHeader
+	}
+	t := &tiffEditor{tiff: append([]byte{}, seg[4+len(exifPrefix):]...)}
+	switch string(t.tiff[:2]) {
+	case "II":
+		t.bo = binary.LittleEndian
+	case "MM":
+		t.bo = binary.BigEndian
+	default:
+		return nil, exif.ErrCorruptHeader
+	}
+	return t, nil
+}
+
+// editor of TIFF data with empty IFD0
+func newEmptyTiffEditor() *tiffEditor {
+	return &tiffEditor{
+		bo:   binary.BigEndian,
+		tiff: []byte{'M', 'M', 0, 42, 0, 0, 0, 8, 0, 0, 0, 0, 0, 0},
+	}
+}
+
+// EXIF APP1 segment with edited TIFF data
+func (t *tiffEditor) segment() ([]byte, error) {
+	if 2+len(exifPrefix)+len(t.tiff) > 0xffff {
+		return nil, errExifTooLong
+	}
+	return newSegment(markerAPP1, append(append([]byte{}, exifPrefix...), t.tiff...)), nil
+}
+
+func (t *tiffEditor) ifd0() int {
+	return int(t.bo.Uint32(t.tiff[4:]))
+}
+
+// entries of IFD at offset and offset of the next IFD
+func (t *tiffEditor) readIFD(ofs int) ([]tiffEntry, int, error) {
+	if ofs < 8 || ofs+2 > len(t.tiff) {
+		return nil, 0, exif.ErrCorruptHeader
+	}
+	n := int(t.bo.Uint16(t.tiff[ofs:]))
+	end := ofs + 2 + n*12
+	if end+4 > len(t.tiff) {
+		return nil, 0, exif.ErrCorruptHeader
+	}
+	entries := make([]tiffEntry, n)
+	for k := range entries {
+		pos := ofs + 2 + k*12
+		e := &entries[k]
+		e.Tag = t.bo.Uint16(t.tiff[pos:])
+		e.Type = t.bo.Uint16(t.tiff[pos+2:])
+		e.Count = t.bo.Uint32(t.tiff[pos+4:])
+		copy(e.Value[:], t.tiff[pos+8:pos+12])
+		e.pos = pos
+	}
+	return entries, int(t.bo.Uint32(t.tiff[end:])), nil
+}
+
+// offset and size of external value of entry, size is 0 for inline or invalid values
+func (t *tiffEditor) external(e tiffEntry) (int, int) {
+	size := int(e.Count) * typeSize(e.Type)
+	if size <= 4 {
+		return 0, 0
+	}
+	ofs := int(t.bo.Uint32(e.Value[:]))
+	if ofs < 8 || ofs+size > len(t.tiff) {
+		return 0, 0
+	}
+	return ofs, size
+}
+
+// value bytes of entry
+func (t *tiffEditor) value(e tiffEntry) []byte {
+	if ofs, size := t.external(e); size > 0 {
+		return t.tiff[ofs : ofs+size]
+	}
+	size := int(e.Count) * typeSize(e.Type)
+	if size > 4 {
+		return nil
+	}
+	return e.Value[:size]
+}
+
+// offset of sub-IFD the entry points to, 0 if there is none
+func (t *tiffEditor) subIFD(entries []tiffEntry, tag uint16) (int, tiffEntry) {
+	for _, e := range entries {
+		if e.Tag == tag && (e.Type == exif.TypeLong || e.Type == 13) && e.Count == 1 {
+			return int(t.bo.Uint32(e.Value[:])), e
+		}
+	}
+	return 0, tiffEntry{}
+}
+
+func (t *tiffEditor) zero(ofs, size int) {
+	for i := ofs; i < ofs+size && i < len(t.tiff); i++ {
+		t.tiff[i] = 0
+	}
+}
+
+// remove entries of IFD at offset for which drop returns true, their external values are zeroed
+func (t *tiffEditor) removeEntries(ofs int, drop func(e tiffEntry) bool) ([]tiffEntry, error) {
+	entries, next, err := t.readIFD(ofs)
+	if err != nil {
+		return nil, err
+	}
+	kept, removed := []tiffEntry{}, []tiffEntry{}
+	for _, e := range entries {
+		if drop(e) {
+			removed = append(removed, e)
+		} else {
+			kept = append(kept, e)
+		}
+	}
+	if len(removed) == 0 {
+		return nil, nil
+	}
+	for _, e := range removed {
+		t.zero(t.external(e))
+	}
+	table := make([]byte, 2+len(entries)*12+4)
+	t.bo.PutUint16(table, uint16(len(kept)))
+	for k := range kept {
+		copy(table[2+k*12:], t.tiff[kept[k].pos:kept[k].pos+12])
+	}
+	t.bo.PutUint32(table[2+len(kept)*12:], uint32(next))
+	copy(t.tiff[ofs:], table)
+	return removed, nil
+}
+
+// zero IFD at offset with its external values
+func (t *tiffEditor) zeroIFD(ofs int) error {
+	entries, _, err := t.readIFD(ofs)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		t.zero(t.external(e))
+	}
+	t.zero(ofs, 2+len(entries)*12+4)
+	return nil
+}
+
+// append IFD with entries sorted by tag, return its offset
+func (t *tiffEditor) appendIFD(entries []tiffEntry, next int) int {
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Tag < entries[j].Tag })
+	if len(t.tiff)%2 != 0 {
+		t.tiff = append(t.tiff, 0)
+	}
+	ofs := len(t.tiff)
+	table := make([]byte, 2+len(entries)*12+4)
+	t.tiff = append(t.tiff, table...)
+	t.bo.PutUint16(t.tiff[ofs:], uint16(len(entries)))
+	for k, e := range entries {
+		if len(e.data) > 4 {
+			if len(t.tiff)%2 != 0 {
+				t.tiff = append(t.tiff, 0)
+			}
+			t.bo.PutUint32(e.Value[:], uint32(len(t.tiff)))
+			t.tiff = append(t.tiff, e.data...)
+		} else if e.data != nil {
+			e.Value = [4]byte{}
+			copy(e.Value[:], e.data)
+		}
+		pos := ofs + 2 + k*12
+		t.bo.PutUint16(t.tiff[pos:], e.Tag)
+		t.bo.PutUint16(t.tiff[pos+2:], e.Type)
+		t.bo.PutUint32(t.tiff[pos+4:], e.Count)
+		copy(t.tiff[pos+8:], e.Value[:])
+	}
+	t.bo.PutUint32(t.tiff[ofs+2+len(entries)*12:], uint32(next))
+	return ofs
+}
+
+// point IFD0 entry tag to sub-IFD at offset, IFD0 is moved to the end if the entry has to be added
+func (t *tiffEditor) setSubIFD(tag uint16, sub int) error {
+	entries, next, err := t.readIFD(t.ifd0())
+	if err != nil {
+		return err
+	}
+	if _, e := t.subIFD(entries, tag); e.Tag == tag {
+		t.bo.PutUint32(t.tiff[e.pos+8:], uint32(sub))
+		return nil
+	}
+	old := t.ifd0()
+	entries = append(entries, tiffEntry{Tag: tag, Type: exif.TypeLong, Count: 1, data: t.long(uint32(sub))})
+	t.bo.PutUint32(t.tiff[4:], uint32(t.appendIFD(entries, next)))
+	t.zero(old, 2+(len(entries)-1)*12+4)
+	return nil
+}
+
+func (t *tiffEditor) long(v uint32) []byte {
+	b := make([]byte, 4)
+	t.bo.PutUint32(b, v)
+	return b
+}
+
+func (t *tiffEditor) rationals(v ...float64) []byte {
+	b := make([]byte, 8*len(v))
+	for i, f := range v {
+		t.bo.PutUint32(b[i*8:], uint32(math.Round(f*10000)))
+		t.bo.PutUint32(b[i*8+4:], 10000)
+	}
+	return b
+}
+
+// GPS tags written by setGPS, stale ones are removed
+var gpsTags = map[uint16]bool{0x00: true, 0x01: true, 0x02: true, 0x03: true, 0x04: true, 0x05: true, 0x06: true, 0x07: true, 0x1d: true}
+
+// write position to GPS IFD keeping GPS tags not describing position
+func (t *tiffEditor) setGPS(g GeoPoint) error {
+	ifd0, _, err := t.readIFD(t.ifd0())
+	if err != nil {
+		return err
+	}
+	entries := []tiffEntry{}
+	if ofs, _ := t.subIFD(ifd0, tagGPSIFD); ofs > 0 {
+		old, _, err := t.readIFD(ofs)
+		if err != nil {
+			return err
+		}
+		for _, e := range old {
+			if gpsTags[e.Tag] {
+				t.zero(t.external(e))
+			} else {
+				entries = append(entries, e)
+			}
+		}
+		t.zero(ofs, 2+len(old)*12+4)
+	}
+	ref := func(v float64, pos, neg string) []byte {
+		if v < 0 {
+			return []byte(neg + "\x00")
+		}
+		return []byte(pos + "\x00")
+	}
+	dms := func(v float64) []byte {
+		// seconds are rounded to rational precision first, so 59.99999" carries into minutes
+		s := math.Round(math.Abs(v) * 3600 * 10000)
+		deg := math.Floor(s / 36000000)
+		min := math.Floor(math.Mod(s, 36000000) / 600000)
+		return t.rationals(deg, min, math.Mod(s, 600000)/10000)
+	}
+	entries = append(entries,
+		tiffEntry{Tag: 0x00, Type: exif.TypeByte, Count: 4, data: []byte{2, 3, 0, 0}},
+		tiffEntry{Tag: 0x01, Type: exif.TypeAscii, Count: 2, data: ref(g.Lat, "N", "S")},
+		tiffEntry{Tag: 0x02, Type: exif.TypeRational, Count: 3, data: dms(g.Lat)},
+		tiffEntry{Tag: 0x03, Type: exif.TypeAscii, Count: 2, data: ref(g.Long, "E", "W")},
+		tiffEntry{Tag: 0x04, Type: exif.TypeRational, Count: 3, data: dms(g.Long)},
+	)
+	if g.HasAlt {
+		below := byte(0)
+		if g.Alt < 0 {
+			below = 1
+		}
+		entries = append(entries,
+			tiffEntry{Tag: 0x05, Type: exif.TypeByte, Count: 1, data: []byte{below}},
+			tiffEntry{Tag: 0x06, Type: exif.TypeRational, Count: 1, data: t.rationals(math.Abs(g.Alt))},
+		)
+	}
+	if !g.Time.IsZero() {
+		u := g.Time.UTC()
+		entries = append(entries,
+			tiffEntry{Tag: 0x07, Type: exif.TypeRational, Count: 3,
+				data: t.rationals(float64(u.Hour()), float64(u.Minute()), float64(u.Second())+float64(u.Nanosecond())/float64(time.Second))},
+			tiffEntry{Tag: 0x1d, Type: exif.TypeAscii, Count: 11, data: []byte(u.Format("2006:01:02") + "\x00")},
+		)
+	}
+	return t.setSubIFD(tagGPSIFD, t.appendIFD(entries, 0))
+}
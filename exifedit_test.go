@@ -0,0 +1,196 @@
+package main
+
+import (
+	"bytes"
+	"image"
+	"image/jpeg"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/tajtiattila/metadata/exif"
+	"github.com/tajtiattila/metadata/exif/exiftag"
+)
+
+// small JPEG with EXIF of x, without EXIF if x is nil
+func testJpeg(t *testing.T, x *exif.Exif) []byte {
+	t.Helper()
+	var b bytes.Buffer
+	if err := jpeg.Encode(&b, image.NewGray(image.Rect(0, 0, 16, 16)), nil); err != nil {
+		t.Fatal(err)
+	}
+	if x == nil {
+		return b.Bytes()
+	}
+	var out bytes.Buffer
+	if err := exif.Copy(&out, bytes.NewReader(b.Bytes()), x); err != nil {
+		t.Fatal(err)
+	}
+	return out.Bytes()
+}
+
+// EXIF with make, dates, serial number and optional GPS position
+func testExif(gps *GeoPoint) *exif.Exif {
+	x := exif.New(16, 16)
+	x.Set(exiftag.Make, exif.Ascii("Canon"))
+	x.Set(BodySerialNumber, exif.Ascii("123456"))
+	x.SetDateTime(time.Date(2020, 1, 2, 3, 4, 5, 0, time.Local))
+	if gps != nil {
+		x.SetLatLong(gps.Lat, gps.Long)
+	}
+	return x
+}
+
+// EXIF of data read back from file
+func readTestExif(t *testing.T, data []byte) *exif.Exif {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), "test.jpg")
+	if err := os.WriteFile(file, data, 0664); err != nil {
+		t.Fatal(err)
+	}
+	x, err := getJpegExif(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return x
+}
+
+func TestSetGPS(t *testing.T) {
+	tests := []struct {
+		name string
+		x    *exif.Exif
+		g    GeoPoint
+	}{
+		{"no EXIF", nil, GeoPoint{Lat: 48.858222, Long: 2.2945}},
+		{"EXIF without GPS", testExif(nil), GeoPoint{Lat: -33.856784, Long: 151.215297, Alt: 12.5, HasAlt: true}},
+		{"GPS replaced", testExif(&GeoPoint{Lat: 10, Long: 20}), GeoPoint{Lat: 35.3606, Long: 138.7274, Alt: 3776, HasAlt: true}},
+		{"below sea level", testExif(nil), GeoPoint{Lat: 31.5, Long: 35.5, Alt: -430.5, HasAlt: true}},
+		{"seconds carry", testExif(nil), GeoPoint{Lat: 55.999999999, Long: -0.99999999999}},
+		{"time", testExif(nil), GeoPoint{Lat: 1, Long: 2, Time: time.Date(2021, 6, 7, 8, 9, 10, 0, time.UTC)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			file := filepath.Join(dir, "test.jpg")
+			if err := os.WriteFile(file, testJpeg(t, tt.x), 0664); err != nil {
+				t.Fatal(err)
+			}
+			if err := updateExifGPS(file, dir, true, tt.g); err != nil {
+				t.Fatal(err)
+			}
+			x, err := getJpegExif(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			info, ok := x.GPSInfo()
+			if !ok {
+				t.Fatal("no GPS position")
+			}
+			if math.Abs(info.Lat-tt.g.Lat) > 1e-6 || math.Abs(info.Long-tt.g.Long) > 1e-6 {
+				t.Errorf("position = %v, %v, want %v, %v", info.Lat, info.Long, tt.g.Lat, tt.g.Long)
+			}
+			if info.Alt.Valid != tt.g.HasAlt || math.Abs(info.Alt.Float64-tt.g.Alt) > 1e-3 {
+				t.Errorf("altitude = %v, want %v", info.Alt, tt.g.Alt)
+			}
+			if !info.Time.Equal(tt.g.Time) {
+				t.Errorf("time = %v, want %v", info.Time, tt.g.Time)
+			}
+			if tt.x != nil {
+				if d, _ := getExifDate(x); d.Format(DateFormat) != "2020:01:02 03:04:05" {
+					t.Errorf("date = %v, want it kept", d)
+				}
+			}
+		})
+	}
+}
+
+func TestStripExif(t *testing.T) {
+	pos := &GeoPoint{Lat: 50.1, Long: 14.4}
+	tests := []struct {
+		name    string
+		groups  int
+		gps     bool // GPS position kept
+		serial  bool // serial number kept
+		removed int  // number of removed descriptions
+	}{
+		{"nothing", 0, true, true, 0},
+		{"GPS", StripGPS, false, true, 1},
+		{"owner", StripOwner, true, false, 1},
+		{"GPS and owner", StripGPS | StripOwner, false, false, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := testJpeg(t, testExif(pos))
+			segs, rest, err := splitJpeg(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			i := findSegment(segs, markerAPP1, exifPrefix)
+			if i < 0 {
+				t.Fatal("no EXIF segment")
+			}
+			seg, removed, err := stripExif(segs[i], tt.groups)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(removed) != tt.removed {
+				t.Errorf("removed = %q, want %d items", removed, tt.removed)
+			}
+			if len(seg) != len(segs[i]) {
+				t.Errorf("segment length = %d, want %d", len(seg), len(segs[i]))
+			}
+			segs[i] = seg
+			x := readTestExif(t, joinJpeg(segs, rest))
+			if _, _, ok := x.LatLong(); ok != tt.gps {
+				t.Errorf("GPS kept = %v, want %v", ok, tt.gps)
+			}
+			if _, ok := x.Tag(BodySerialNumber).Ascii(); ok != tt.serial {
+				t.Errorf("serial number kept = %v, want %v", ok, tt.serial)
+			}
+			if s, _ := x.Tag(exiftag.Make).Ascii(); s != "Canon" {
+				t.Errorf("make = %q, want it kept", s)
+			}
+			if d, _ := getExifDate(x); d.Format(DateFormat) != "2020:01:02 03:04:05" {
+				t.Errorf("date = %v, want it kept", d)
+			}
+		})
+	}
+}
+
+func TestSetGPSSeconds(t *testing.T) {
+	tests := []struct {
+		v             float64
+		deg, min, sec uint32 // sec in 1/10000
+	}{
+		{0, 0, 0, 0},
+		{12.3456789, 12, 20, 444440},
+		{37.0166666666, 37, 1, 0},
+		{55.999999999, 56, 0, 0},
+		{-89.99999999, 90, 0, 0},
+		{0.99999999999, 1, 0, 0},
+	}
+	for _, tt := range tests {
+		e := newEmptyTiffEditor()
+		if err := e.setGPS(GeoPoint{Lat: tt.v, Long: tt.v}); err != nil {
+			t.Fatal(err)
+		}
+		ifd0, _, _ := e.readIFD(e.ifd0())
+		gps, _ := e.subIFD(ifd0, tagGPSIFD)
+		entries, _, err := e.readIFD(gps)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, entry := range entries {
+			if entry.Tag != 0x02 && entry.Tag != 0x04 {
+				continue
+			}
+			b := e.value(entry)
+			deg, min, sec := e.bo.Uint32(b)/10000, e.bo.Uint32(b[8:])/10000, e.bo.Uint32(b[16:])
+			if deg != tt.deg || min != tt.min || sec != tt.sec {
+				t.Errorf("%v: %d° %d' %d/10000\", want %d° %d' %d/10000\"", tt.v, deg, min, sec, tt.deg, tt.min, tt.sec)
+			}
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+)
+
+// Filename date patterns. Tokens YYYY, MM, DD, hh, mm, ss match date and time digits,
+// other characters match literally, pattern may match anywhere in the file name
+var namePatterns = []string{
+	"YYYYMMDD_hhmmss",        // IMG_20230514_102201.jpg
+	"YYYYMMDD-hhmmss",        // 20230514-102201.jpg
+	"YYYY-MM-DD-hh-mm-ss",    // Screenshot_2023-05-14-10-22-01.png
+	"YYYY-MM-DD_hh-mm-ss",    // photo_2023-05-14_10-22-01.jpg
+	"YYYY-MM-DD at hh.mm.ss", // Screenshot 2023-05-14 at 10.22.01.png
+	"YYYY-MM-DD hh.mm.ss",    // 2023-05-14 10.22.01.jpg
+	"YYYYMMDDhhmmss",         // 20230514102201.jpg
+	"YYYYMMDD",               // IMG-20230514-WA0003.jpg
+	"YYYY-MM-DD",             // 2023-05-14.jpg
+}
+
+var nameTokens = []struct {
+	token string
+	field int
+}{
+	{"YYYY", 0}, {"MM", 1}, {"DD", 2}, {"hh", 3}, {"mm", 4}, {"ss", 5},
+}
+
+// compiled pattern with date field of each regexp group
+type namePattern struct {
+	re     *regexp.Regexp
+	fields []int
+}
+
+// compile token pattern to regexp matching digits not surrounded by other digits
+func compileNamePattern(pattern string) *namePattern {
+	np := &namePattern{}
+	expr := `(?:^|\D)`
+	lit := ""
+	for i := 0; i < len(pattern); {
+		found := false
+		for _, t := range nameTokens {
+			if strings.HasPrefix(pattern[i:], t.token) {
+				expr += regexp.QuoteMeta(lit) + `(\d{` + strconv.Itoa(len(t.token)) + `})`
+				lit = ""
+				np.fields = append(np.fields, t.field)
+				i += len(t.token)
+				found = true
+				break
+			}
+		}
+		if !found {
+			lit += pattern[i : i+1]
+			i++
+		}
+	}
+	if len(np.fields) == 0 {
+		return nil
+	}
+	np.re = regexp.MustCompile(expr + regexp.QuoteMeta(lit) + `(?:\D|$)`)
+	return np
+}
+
+// parse date from file name, ok is false when name matches none of the patterns
+func (np *namePattern) parse(name string) (t time.Time, ok bool) {
+	m := np.re.FindStringSubmatch(name)
+	if m == nil {
+		return time.Time{}, false
+	}
+	v := []int{0, 1, 1, 0, 0, 0}
+	for i, f := range np.fields {
+		v[f], _ = strconv.Atoi(m[i+1])
+	}
+	if v[0] < 1900 || v[0] > 2100 {
+		return time.Time{}, false
+	}
+	t = time.Date(v[0], time.Month(v[1]), v[2], v[3], v[4], v[5], 0, time.Local)
+	if t.Month() != time.Month(v[1]) || t.Day() != v[2] || t.Hour() != v[3] || t.Minute() != v[4] || t.Second() != v[5] {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// user defined filename patterns, one per line
+func userNamePatterns() []string {
+	patterns := []string{}
+	for _, s := range strings.Split(fyne.CurrentApp().Preferences().String(prefNamePatterns), "\n") {
+		if s = strings.TrimSpace(s); s != "" {
+			patterns = append(patterns, s)
+		}
+	}
+	return patterns
+}
+
+// compile user defined and built-in patterns, user defined go first
+func compileNamePatterns() []*namePattern {
+	compiled := []*namePattern{}
+	for _, s := range append(userNamePatterns(), namePatterns...) {
+		if np := compileNamePattern(s); np != nil {
+			compiled = append(compiled, np)
+		}
+	}
+	return compiled
+}
+
+// get date from file name using patterns
+func getNameDate(file string, patterns []*namePattern) Date {
+	name := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+	for _, np := range patterns {
+		if t, ok := np.parse(name); ok {
+			return newDate(t, false, false)
+		}
+	}
+	return Date{}
+}
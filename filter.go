@@ -0,0 +1,214 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// List filter predicates
+const (
+	FilterDropped     = "Dropped"
+	FilterNoExifDate  = "No EXIF date"
+	FilterDatesDiffer = "EXIF and file dates differ"
+	FilterEntered     = "Entered date set"
+)
+
+const AllCameras = "All cameras"
+
+// photo list filter, zero value matches all photos
+type photoFilter struct {
+	Text       string          // file name substring, case insensitive
+	Predicates map[string]bool // all checked predicates must hold
+	From, To   Date            // chosen date range, zero is open end
+	Camera     string          // camera model
+}
+
+func (f photoFilter) match(p *Photo) bool {
+	if f.Text != "" && !strings.Contains(strings.ToLower(filepath.Base(p.File)), strings.ToLower(f.Text)) {
+		return false
+	}
+	if f.Predicates[FilterDropped] && !p.Droped {
+		return false
+	}
+	if f.Predicates[FilterNoExifDate] && !p.Dates[ChoiceExifDate].IsZero() {
+		return false
+	}
+	if f.Predicates[FilterDatesDiffer] && !datesDiffer(p.Dates[ChoiceExifDate], p.Dates[ChoiceFileDate]) {
+		return false
+	}
+	if f.Predicates[FilterEntered] && p.Dates[ChoiceEnteredDate].IsZero() {
+		return false
+	}
+	if !f.From.IsZero() || !f.To.IsZero() {
+		d := p.Dates[p.DateChoice]
+		if d.IsZero() || !f.From.IsZero() && d.Time.Before(f.From.Time) || !f.To.IsZero() && d.Time.After(f.To.Time) {
+			return false
+		}
+	}
+	if f.Camera != "" && p.Camera != f.Camera {
+		return false
+	}
+	return true
+}
+
+// photos matching filter in list order
+func (f photoFilter) apply(list []*Photo) []*Photo {
+	view := make([]*Photo, 0, len(list))
+	for _, p := range list {
+		if f.match(p) {
+			view = append(view, p)
+		}
+	}
+	return view
+}
+
+// true if both dates are set and differ by a second or more
+func datesDiffer(a, b Date) bool {
+	if a.IsZero() || b.IsZero() {
+		return false
+	}
+	d := a.Time.Sub(b.Time)
+	return d >= time.Second || d <= -time.Second
+}
+
+// parse date range end, date without time is taken as start or end of the day
+func parseRangeDate(s string, end bool) (Date, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return Date{}, nil
+	}
+	if len(s) == len("2006:01:02") {
+		if end {
+			s += " 23:59:59"
+		} else {
+			s += " 00:00:00"
+		}
+	}
+	return parseDate(s)
+}
+
+// distinct camera models of the list
+func (l *PhotoList) cameraModels() []string {
+	models := []string{}
+	seen := map[string]bool{}
+	for _, p := range l.List {
+		if p.Camera != "" && !seen[p.Camera] {
+			seen[p.Camera] = true
+			models = append(models, p.Camera)
+		}
+	}
+	sort.Strings(models)
+	return models
+}
+
+// create List tab filter bar
+func (l *PhotoList) newFilterBar() *fyne.Container {
+	l.filterCount = widget.NewLabel("")
+	search := widget.NewEntry()
+	search.SetPlaceHolder("File name")
+	predicates := widget.NewCheckGroup([]string{FilterDropped, FilterNoExifDate, FilterDatesDiffer, FilterEntered}, nil)
+	predicates.Horizontal = true
+	from, to := widget.NewEntry(), widget.NewEntry()
+	from.SetPlaceHolder("From " + DateFormat[:10])
+	to.SetPlaceHolder("To " + DateFormat[:10])
+	from.Validator = func(s string) error { _, err := parseRangeDate(s, false); return err }
+	to.Validator = func(s string) error { _, err := parseRangeDate(s, true); return err }
+	camera := widget.NewSelect(append([]string{AllCameras}, l.cameraModels()...), nil)
+	camera.SetSelected(AllCameras)
+
+	update := func() {
+		f := photoFilter{Text: strings.TrimSpace(search.Text), Predicates: map[string]bool{}}
+		for _, s := range predicates.Selected {
+			f.Predicates[s] = true
+		}
+		var err error
+		if f.From, err = parseRangeDate(from.Text, false); err != nil {
+			return
+		}
+		if f.To, err = parseRangeDate(to.Text, true); err != nil {
+			return
+		}
+		if camera.Selected != AllCameras {
+			f.Camera = camera.Selected
+		}
+		l.setFilter(f)
+	}
+	search.OnChanged = func(string) { update() }
+	predicates.OnChanged = func([]string) { update() }
+	from.OnChanged = func(string) { update() }
+	to.OnChanged = func(string) { update() }
+	camera.OnChanged = func(string) { update() }
+	reset := widget.NewButtonWithIcon("", theme.ContentClearIcon(), func() {
+		search.SetText("")
+		predicates.SetSelected(nil)
+		from.SetText("")
+		to.SetText("")
+		camera.SetSelected(AllCameras)
+	})
+	l.updateFilterCount()
+
+	selectAll := widget.NewButton("Select all", func() { l.selectAllRows(true) })
+	selectNone := widget.NewButton("Select none", func() { l.selectAllRows(false) })
+
+	dates := container.NewGridWithColumns(2, from, to)
+	return container.NewVBox(
+		container.NewBorder(nil, nil, nil, container.NewHBox(camera, reset), search),
+		container.NewBorder(nil, nil, nil, container.NewHBox(l.filterCount, selectAll, selectNone), container.NewHBox(predicates, dates)),
+	)
+}
+
+// filter photo list, Choice frame is reloaded from the first photo
+func (l *PhotoList) setFilter(f photoFilter) {
+	for _, p := range l.selection() {
+		p.Img = nil
+	}
+	l.filter = f
+	l.View = f.apply(l.List)
+	for p := range l.rowSelection {
+		if !f.match(p) {
+			delete(l.rowSelection, p)
+		}
+	}
+	l.FramePos = 0
+	if l.FrameSize == 0 || l.FrameSize > len(l.View) {
+		l.FrameSize = InitFrameSize
+	}
+	if l.FrameSize > len(l.View) {
+		l.FrameSize = len(l.View)
+	}
+	for _, p := range l.selection() {
+		p.Img = p.img(l.FrameSize)
+		if p.Droped {
+			p.Img.Translucency = 0.5
+		}
+	}
+	l.Frame.RemoveAll()
+	for _, p := range l.selection() {
+		l.Frame.Add(p.FrameColumn())
+	}
+	if l.FrameSize == 0 { // no zero grid columns, see initFrame
+		l.Frame.Layout = layout.NewGridLayoutWithColumns(1)
+	} else {
+		l.Frame.Layout = layout.NewGridLayoutWithColumns(l.FrameSize)
+	}
+	l.Frame.Refresh()
+	l.updateFilterCount()
+	if l.table != nil {
+		l.table.Refresh()
+	}
+}
+
+func (l *PhotoList) updateFilterCount() {
+	if l.filterCount != nil {
+		l.filterCount.SetText(fmt.Sprintf("%d of %d", len(l.View), len(l.List)) + l.selectionCount())
+	}
+}
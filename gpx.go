@@ -0,0 +1,289 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/widget"
+)
+
+// default longest time between photo and track point to match
+const DefaultMaxGPSGap = 5 * time.Minute
+
+// Geographic position
+type GeoPoint struct {
+	Lat    float64
+	Long   float64
+	Alt    float64
+	HasAlt bool
+	Time   time.Time // GPS fix time, zero if unknown
+}
+
+func (g GeoPoint) String() string {
+	return formatLatLong(g.Lat, g.Long)
+}
+
+// GPX track point
+type gpxPoint struct {
+	Lat  float64  `xml:"lat,attr"`
+	Long float64  `xml:"lon,attr"`
+	Ele  *float64 `xml:"ele"`
+	Time string   `xml:"time"`
+}
+
+type gpxFile struct {
+	Tracks []struct {
+		Segments []struct {
+			Points []gpxPoint `xml:"trkpt"`
+		} `xml:"trkseg"`
+	} `xml:"trk"`
+}
+
+// load timed points of all GPX file tracks ordered by time
+func loadGPX(file string) ([]GeoPoint, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var g gpxFile
+	if err = xml.NewDecoder(bytes.NewReader(data)).Decode(&g); err != nil {
+		return nil, fmt.Errorf("%s: %w", filepath.Base(file), err)
+	}
+	track := []GeoPoint{}
+	for _, t := range g.Tracks {
+		for _, s := range t.Segments {
+			for _, p := range s.Points {
+				tm, err := time.Parse(time.RFC3339, strings.TrimSpace(p.Time))
+				if err != nil {
+					continue
+				}
+				gp := GeoPoint{Lat: p.Lat, Long: p.Long, Time: tm.UTC()}
+				if p.Ele != nil {
+					gp.Alt, gp.HasAlt = *p.Ele, true
+				}
+				track = append(track, gp)
+			}
+		}
+	}
+	if len(track) == 0 {
+		return nil, fmt.Errorf("%s: no timed track points", filepath.Base(file))
+	}
+	sort.SliceStable(track, func(i, j int) bool { return track[i].Time.Before(track[j].Time) })
+	return track, nil
+}
+
+// position at time t linearly interpolated between track points,
+// nearest point is used when neighbours are more than maxGap apart, ok is false if no point is within maxGap
+func matchTrack(track []GeoPoint, t time.Time, maxGap time.Duration) (g GeoPoint, ok bool) {
+	i := sort.Search(len(track), func(i int) bool { return !track[i].Time.Before(t) })
+	near := func(p GeoPoint) (GeoPoint, bool) {
+		d := p.Time.Sub(t)
+		if d < 0 {
+			d = -d
+		}
+		p.Time = t.UTC()
+		return p, d <= maxGap
+	}
+	switch {
+	case i == len(track):
+		return near(track[i-1])
+	case i == 0 || track[i].Time.Equal(t):
+		return near(track[i])
+	}
+	a, b := track[i-1], track[i]
+	span := b.Time.Sub(a.Time)
+	if span > maxGap {
+		if t.Sub(a.Time) < b.Time.Sub(t) {
+			return near(a)
+		}
+		return near(b)
+	}
+	f := float64(t.Sub(a.Time)) / float64(span)
+	g = GeoPoint{
+		Lat:    a.Lat + (b.Lat-a.Lat)*f,
+		Long:   a.Long + (b.Long-a.Long)*f,
+		Alt:    a.Alt + (b.Alt-a.Alt)*f,
+		HasAlt: a.HasAlt && b.HasAlt,
+		Time:   t.UTC(),
+	}
+	return g, true
+}
+
+// write GPS position to EXIF of photo file rewriting only GPS IFD,
+// file is moved to backup first unless backedUp tells it was done already
+func updateExifGPS(file, backupDirName string, backedUp bool, g GeoPoint) error {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	segs, rest, err := splitJpeg(data)
+	if err != nil {
+		return err
+	}
+	i := findSegment(segs, markerAPP1, exifPrefix)
+	t := newEmptyTiffEditor()
+	if i >= 0 {
+		if t, err = newTiffEditor(segs[i]); err != nil {
+			return err
+		}
+	}
+	if err = t.setGPS(g); err != nil {
+		return err
+	}
+	seg, err := t.segment()
+	if err != nil {
+		return err
+	}
+	if i >= 0 {
+		segs[i] = seg
+	} else {
+		at := 1 // EXIF goes first, after JFIF APP0 if present
+		if len(segs) > 1 && segs[1][1] == 0xe0 {
+			at = 2
+		}
+		segs = append(segs[:at], append([][]byte{seg}, segs[at:]...)...)
+	}
+	if !backedUp {
+		if err = os.Rename(file, filepath.Join(backupDirName, filepath.Base(file))); err != nil {
+			return err
+		}
+	}
+	return os.WriteFile(file, joinJpeg(segs, rest), 0664)
+}
+
+// XMP GPS coordinate "DDD,MM.mmmmmmK"
+func xmpGPSCoordinate(v float64, pos, neg string) string {
+	ref := pos
+	if v < 0 {
+		ref, v = neg, -v
+	}
+	deg := math.Floor(v)
+	return fmt.Sprintf("%d,%.6f%s", int(deg), (v-deg)*60, ref)
+}
+
+// parse XMP GPS coordinate
+func parseXMPGPSCoordinate(s string) (float64, bool) {
+	s = strings.TrimSpace(s)
+	if len(s) < 2 {
+		return 0, false
+	}
+	ref := s[len(s)-1]
+	deg, min, found := strings.Cut(s[:len(s)-1], ",")
+	if !found {
+		return 0, false
+	}
+	d, err1 := strconv.ParseFloat(deg, 64)
+	m, err2 := strconv.ParseFloat(min, 64)
+	if err1 != nil || err2 != nil {
+		return 0, false
+	}
+	v := d + m/60
+	if ref == 'S' || ref == 'W' {
+		v = -v
+	}
+	return v, true
+}
+
+// choose GPX file and preview positions matched to photo dates in the List tab
+func (l *PhotoList) geotagPhotos() {
+	dlg := dialog.NewFileOpen(func(r fyne.URIReadCloser, err error) {
+		if err != nil || r == nil {
+			return
+		}
+		r.Close()
+		track, err := loadGPX(r.URI().Path())
+		if err != nil {
+			dialog.ShowError(err, wMain)
+			return
+		}
+		l.chooseGeotagOptions(track)
+	}, wMain)
+	dlg.SetFilter(storage.NewExtensionFileFilter([]string{".gpx", ".GPX"}))
+	if folder, err := storage.ListerForURI(storage.NewFileURI(l.Folder)); err == nil {
+		dlg.SetLocation(folder)
+	}
+	dlg.Resize(fyne.NewSize(800, 600))
+	dlg.Show()
+}
+
+// show track matching options dialog
+func (l *PhotoList) chooseGeotagOptions(track []GeoPoint) {
+	durationValidator := func(s string) error {
+		if strings.TrimSpace(s) == "" {
+			return nil
+		}
+		_, err := time.ParseDuration(strings.TrimSpace(s))
+		return err
+	}
+	offset := widget.NewEntry()
+	offset.SetPlaceHolder("e.g. 1m30s or -2h")
+	offset.Validator = durationValidator
+	maxGap := widget.NewEntry()
+	maxGap.SetText(DefaultMaxGPSGap.String())
+	maxGap.Validator = durationValidator
+	overwrite := widget.NewCheck("Replace existing positions", nil)
+	info := fmt.Sprintf("%d points from %s to %s", len(track),
+		track[0].Time.Local().Format(DateFormat), track[len(track)-1].Time.Local().Format(DateFormat))
+	items := []*widget.FormItem{
+		widget.NewFormItem("Track", widget.NewLabel(info)),
+		widget.NewFormItem("Camera clock ahead by", offset),
+		widget.NewFormItem("Max gap", maxGap),
+		widget.NewFormItem("", overwrite),
+	}
+	dialog.ShowForm("Geotag from GPX", "Preview", "Cancel", items, func(ok bool) {
+		if !ok {
+			return
+		}
+		shift, _ := time.ParseDuration(strings.TrimSpace(offset.Text))
+		gap, err := time.ParseDuration(strings.TrimSpace(maxGap.Text))
+		if err != nil {
+			gap = DefaultMaxGPSGap
+		}
+		l.previewGeotags(track, shift, gap, overwrite.Checked)
+	}, wMain)
+}
+
+// preview positions matched to chosen photo dates corrected by camera clock offset
+func (l *PhotoList) previewGeotags(track []GeoPoint, offset, maxGap time.Duration, overwrite bool) {
+	matched := map[*Photo]GeoPoint{}
+	unmatched := 0
+	for _, p := range l.List {
+		d := p.Dates[p.DateChoice]
+		if p.Droped || d.IsZero() || p.GPS != nil && !overwrite {
+			continue
+		}
+		if g, ok := matchTrack(track, d.Add(-offset).Time, maxGap); ok {
+			matched[p] = g
+		} else {
+			unmatched++
+		}
+	}
+	if len(matched) == 0 {
+		dialog.ShowInformation("Geotag from GPX", "No photo dates match the track", wMain)
+		return
+	}
+	l.pending = nil
+	l.pendingGPS = matched
+	l.previewLabel.SetText(fmt.Sprintf("%d matched positions are shown in italic in GPS column, %d photos not matched", len(matched), unmatched))
+	l.previewBar.Show()
+	l.table.Refresh()
+	l.applyPending = func() {
+		for p, g := range matched {
+			g := g
+			p.GPS = &g
+			p.gpsChanged = true
+		}
+		l.refresh()
+	}
+}
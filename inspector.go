@@ -0,0 +1,104 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+)
+
+const inspectorWidth = 360
+
+// Metadata inspector panel shown beside the frame
+type metaInspector struct {
+	panel  *fyne.Container
+	title  *widget.Label
+	status *widget.Label
+	search *widget.Entry
+	list   *widget.List
+	photo  *Photo
+	fields []MetaField
+	shown  []MetaField
+}
+
+func newMetaInspector() *metaInspector {
+	m := &metaInspector{
+		title:  widget.NewLabelWithStyle("", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		status: widget.NewLabel("Tap a field to copy its value"),
+		search: widget.NewEntry(),
+	}
+	m.title.Wrapping = fyne.TextTruncate
+	m.search.SetPlaceHolder("Search fields")
+	m.search.OnChanged = func(string) { m.filter() }
+	m.list = widget.NewList(
+		func() int { return len(m.shown) },
+		func() fyne.CanvasObject {
+			name := widget.NewLabelWithStyle("", fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+			value := widget.NewLabel("")
+			value.Wrapping = fyne.TextTruncate
+			return container.NewBorder(nil, nil, name, nil, value)
+		},
+		func(i widget.ListItemID, o fyne.CanvasObject) {
+			f := m.shown[i]
+			c := o.(*fyne.Container)
+			c.Objects[1].(*widget.Label).SetText(f.Group + " " + f.Name)
+			c.Objects[0].(*widget.Label).SetText(f.Value)
+		},
+	)
+	m.list.OnSelected = func(i widget.ListItemID) {
+		f := m.shown[i]
+		wMain.Clipboard().SetContent(f.Value)
+		m.status.SetText("Copied " + f.Name)
+		m.list.UnselectAll()
+	}
+	width := canvas.NewRectangle(nil)
+	width.SetMinSize(fyne.NewSize(inspectorWidth, 0))
+	m.panel = container.NewMax(width,
+		container.NewBorder(container.NewVBox(m.title, m.search), m.status, nil, nil, m.list))
+	m.panel.Hide()
+	return m
+}
+
+// show metadata of the photo
+func (m *metaInspector) inspect(p *Photo) {
+	m.photo = p
+	m.title.SetText(filepath.Base(p.File))
+	m.fields = readMetaFields(p.File)
+	m.status.SetText("Tap a field to copy its value")
+	m.filter()
+}
+
+// show fields with group, name or value containing search text
+func (m *metaInspector) filter() {
+	text := strings.ToLower(strings.TrimSpace(m.search.Text))
+	m.shown = m.shown[:0]
+	for _, f := range m.fields {
+		if text == "" || strings.Contains(strings.ToLower(f.Group+" "+f.Name+" "+f.Value), text) {
+			m.shown = append(m.shown, f)
+		}
+	}
+	m.list.ScrollToTop()
+	m.list.Refresh()
+}
+
+// show or hide inspector panel, frame photo is inspected if none was chosen
+func (l *PhotoList) toggleInspector() {
+	m := l.inspector
+	if m.panel.Visible() {
+		m.panel.Hide()
+		return
+	}
+	if m.photo == nil && len(l.View) > 0 {
+		m.inspect(l.View[l.FramePos])
+	}
+	m.panel.Show()
+}
+
+// show photo metadata in inspector panel
+func (l *PhotoList) inspectPhoto(p *Photo) {
+	l.inspector.inspect(p)
+	l.inspector.panel.Show()
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+)
+
+// dates for photos without EXIF date linearly interpolated in current order
+// between the nearest photos with EXIF date before and after them
+func (l *PhotoList) interpolationChanges() []DateChange {
+	changes := []DateChange{}
+	prev := -1
+	for i, p := range l.List {
+		if p.Dates[ChoiceExifDate].IsZero() {
+			continue
+		}
+		if prev >= 0 && i-prev > 1 {
+			from := l.List[prev].Dates[l.List[prev].DateChoice]
+			to := p.Dates[p.DateChoice]
+			step := (to.Sub(from.Time) / time.Duration(i-prev)).Truncate(time.Second)
+			for k := prev + 1; k < i; k++ {
+				if !l.List[k].Droped {
+					changes = append(changes, DateChange{Photo: l.List[k], Date: from.Add(step * time.Duration(k-prev))})
+				}
+			}
+		}
+		prev = i
+	}
+	return changes
+}
+
+// preview interpolated dates in the List tab entered date column
+func (l *PhotoList) interpolateDates() {
+	changes := l.interpolationChanges()
+	if len(changes) == 0 {
+		dialog.ShowInformation("Interpolate dates", "There are no photos without EXIF date between dated photos", wMain)
+		return
+	}
+	l.pendingGPS = nil
+	l.pending = map[*Photo]Date{}
+	for _, c := range changes {
+		l.pending[c.Photo] = c.Date
+	}
+	l.previewLabel.SetText(fmt.Sprintf("%d interpolated dates are shown in italic in Entered Date column", len(changes)))
+	l.previewBar.Show()
+	l.table.Refresh()
+	l.applyPending = func() {
+		l.applyDateChanges(changes)
+	}
+}
+
+// bar with preview apply and discard buttons, hidden until there is a preview
+func (l *PhotoList) newPreviewBar() *fyne.Container {
+	l.previewLabel = widget.NewLabel("")
+	finish := func(apply bool) {
+		if apply && l.applyPending != nil {
+			l.applyPending()
+		}
+		l.pending = nil
+		l.pendingGPS = nil
+		l.applyPending = nil
+		l.previewBar.Hide()
+		l.table.Refresh()
+	}
+	l.previewBar = container.NewHBox(
+		l.previewLabel,
+		layout.NewSpacer(),
+		widget.NewButton("Apply", func() { finish(true) }),
+		widget.NewButton("Discard", func() { finish(false) }),
+	)
+	l.previewBar.Hide()
+	return l.previewBar
+}
@@ -0,0 +1,307 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+var photoshopPrefix = []byte("Photoshop 3.0\x00")
+
+var errIPTCTooLong = errors.New("IPTC data too long for JPEG segment")
+
+// Photoshop image resource with IPTC-NAA record
+const resourceIPTC = 0x0404
+
+// IPTC IIM application record (2) dataset names, named datasets are text
+var iptcNames = map[byte]string{
+	5:   "Object Name",
+	10:  "Urgency",
+	15:  "Category",
+	20:  "Supplemental Category",
+	25:  "Keywords",
+	40:  "Special Instructions",
+	55:  "Date Created",
+	60:  "Time Created",
+	80:  "By-line",
+	85:  "By-line Title",
+	90:  "City",
+	92:  "Sub-location",
+	95:  "Province-State",
+	100: "Country Code",
+	101: "Country Name",
+	103: "Original Transmission Reference",
+	105: "Headline",
+	110: "Credit",
+	115: "Source",
+	116: "Copyright Notice",
+	118: "Contact",
+	120: "Caption-Abstract",
+	122: "Writer-Editor",
+}
+
+// IPTC IIM dataset
+type iptcRecord struct {
+	Record  byte
+	Dataset byte
+	Value   []byte
+}
+
+func (r iptcRecord) name() string {
+	if n, ok := iptcNames[r.Dataset]; ok && r.Record == 2 {
+		return n
+	}
+	return fmt.Sprintf("%d:%d", r.Record, r.Dataset)
+}
+
+func (r iptcRecord) String() string {
+	if r.Record == 2 && r.Dataset == 0 && len(r.Value) == 2 {
+		return fmt.Sprint(binary.BigEndian.Uint16(r.Value)) // record version
+	}
+	return truncate(strings.TrimRight(string(r.Value), "\x00"))
+}
+
+// Photoshop image resource
+type photoshopResource struct {
+	ID   uint16
+	Name []byte // padded pascal string
+	Data []byte
+}
+
+// split Photoshop image resources block into resources
+func parsePhotoshopResources(p []byte) []photoshopResource {
+	resources := []photoshopResource{}
+	for len(p) >= 12 && string(p[:4]) == "8BIM" {
+		id := binary.BigEndian.Uint16(p[4:])
+		n := int(p[6])
+		n += 1 - n%2 // name is a padded pascal string
+		if 7+n+4 > len(p) {
+			break
+		}
+		size := int(binary.BigEndian.Uint32(p[7+n:]))
+		start := 7 + n + 4
+		if start+size > len(p) {
+			break
+		}
+		resources = append(resources, photoshopResource{ID: id, Name: p[6 : 7+n], Data: p[start : start+size]})
+		p = p[start+size:]
+		if size%2 == 1 && len(p) > 0 {
+			p = p[1:]
+		}
+	}
+	return resources
+}
+
+// encode Photoshop image resources block
+func encodePhotoshopResources(resources []photoshopResource) []byte {
+	var b bytes.Buffer
+	for _, r := range resources {
+		b.WriteString("8BIM")
+		binary.Write(&b, binary.BigEndian, r.ID)
+		if len(r.Name) == 0 {
+			b.Write([]byte{0, 0})
+		} else {
+			b.Write(r.Name)
+		}
+		binary.Write(&b, binary.BigEndian, uint32(len(r.Data)))
+		b.Write(r.Data)
+		if len(r.Data)%2 == 1 {
+			b.WriteByte(0)
+		}
+	}
+	return b.Bytes()
+}
+
+// decode IPTC IIM datasets of Photoshop APP13 segment payload
+func parseIPTC(p []byte) []iptcRecord {
+	for _, r := range parsePhotoshopResources(p) {
+		if r.ID == resourceIPTC {
+			records, _ := parseIPTCRecords(r.Data)
+			return records
+		}
+	}
+	return []iptcRecord{}
+}
+
+// decode IIM datasets, tail is data from the first malformed dataset on
+func parseIPTCRecords(data []byte) (records []iptcRecord, tail []byte) {
+	records = []iptcRecord{}
+	for len(data) >= 5 && data[0] == 0x1c {
+		start, size := 5, int(binary.BigEndian.Uint16(data[3:]))
+		if size&0x8000 != 0 {
+			// extended dataset, size is length of the value length
+			n := size & 0x7fff
+			if n == 0 || n > 4 || 5+n > len(data) {
+				break
+			}
+			start, size = 5+n, 0
+			for _, c := range data[5 : 5+n] {
+				size = size<<8 | int(c)
+			}
+		}
+		if start+size > len(data) {
+			break
+		}
+		records = append(records, iptcRecord{Record: data[1], Dataset: data[2], Value: data[start : start+size]})
+		data = data[start+size:]
+	}
+	return records, data
+}
+
+// encode IIM datasets followed by unparsed tail kept as is
+func encodeIPTCRecords(records []iptcRecord, tail []byte) []byte {
+	var b bytes.Buffer
+	for _, r := range records {
+		b.Write([]byte{0x1c, r.Record, r.Dataset})
+		if len(r.Value) > 0x7fff {
+			b.Write([]byte{0x80, 4}) // extended dataset with 4 byte value length
+			binary.Write(&b, binary.BigEndian, uint32(len(r.Value)))
+		} else {
+			binary.Write(&b, binary.BigEndian, uint16(len(r.Value)))
+		}
+		b.Write(r.Value)
+	}
+	b.Write(tail)
+	return b.Bytes()
+}
+
+// IPTC IIM datasets written from photo descriptions
+const (
+	iptcObjectName      = 5
+	iptcKeywords        = 25
+	iptcCaptionAbstract = 120
+)
+
+// UTF-8 coded character set (1:90) escape sequence
+var iptcUTF8 = []byte("\x1b%G")
+
+// coded character set of IIM data
+type iptcCharset struct {
+	declared bool // 1:90 dataset is present
+	utf8     bool // declared set is UTF-8
+}
+
+func iptcCharsetOf(records []iptcRecord) iptcCharset {
+	c := iptcCharset{}
+	for _, r := range records {
+		if r.Record == 1 && r.Dataset == 90 {
+			c = iptcCharset{declared: true, utf8: bytes.Equal(r.Value, iptcUTF8)}
+		}
+	}
+	return c
+}
+
+// UTF-8 value of text dataset, text in other character sets is taken as Latin-1,
+// undeclared valid UTF-8 text and binary or unknown datasets are returned as is
+func (c iptcCharset) decode(r iptcRecord) []byte {
+	if _, text := iptcNames[r.Dataset]; r.Record != 2 || !text || c.utf8 || !c.declared && utf8.Valid(r.Value) {
+		return r.Value
+	}
+	return latin1ToUTF8(r.Value)
+}
+
+// dataset text decoded with character set of its IIM data
+func (r iptcRecord) text(c iptcCharset) string {
+	return strings.TrimRight(string(c.decode(r)), "\x00")
+}
+
+// set title, caption and keywords datasets of IIM data keeping other datasets,
+// text is marked as UTF-8 and cut to dataset length limits, kept text in other
+// character sets is transcoded
+func setIPTCRecords(records []iptcRecord, title, caption string, keywords []string) []iptcRecord {
+	charset := iptcCharsetOf(records)
+	out := []iptcRecord{{Record: 1, Dataset: 90, Value: iptcUTF8}}
+	hasVersion := false
+	for _, r := range records {
+		switch {
+		case r.Record == 1 && r.Dataset == 90:
+			continue
+		case r.Record == 2 && (r.Dataset == iptcObjectName || r.Dataset == iptcKeywords || r.Dataset == iptcCaptionAbstract):
+			continue
+		case r.Record == 2 && r.Dataset == 0:
+			hasVersion = true
+		default:
+			r.Value = charset.decode(r)
+		}
+		out = append(out, r)
+	}
+	if !hasVersion {
+		out = append(out, iptcRecord{Record: 2, Dataset: 0, Value: []byte{0, 4}})
+	}
+	if title != "" {
+		out = append(out, iptcRecord{Record: 2, Dataset: iptcObjectName, Value: cutUTF8(title, 64)})
+	}
+	for _, k := range keywords {
+		out = append(out, iptcRecord{Record: 2, Dataset: iptcKeywords, Value: cutUTF8(k, 64)})
+	}
+	if caption != "" {
+		out = append(out, iptcRecord{Record: 2, Dataset: iptcCaptionAbstract, Value: cutUTF8(caption, 2000)})
+	}
+	// records in order, record version dataset is the first one of its record
+	rank := func(r iptcRecord) int {
+		if r.Dataset == 0 {
+			return int(r.Record) * 2
+		}
+		return int(r.Record)*2 + 1
+	}
+	sort.SliceStable(out, func(i, j int) bool { return rank(out[i]) < rank(out[j]) })
+	return out
+}
+
+func latin1ToUTF8(p []byte) []byte {
+	b := make([]byte, 0, len(p))
+	for _, c := range p {
+		b = utf8.AppendRune(b, rune(c))
+	}
+	return b
+}
+
+// text bytes not longer than n, not splitting characters
+func cutUTF8(s string, n int) []byte {
+	if len(s) <= n {
+		return []byte(s)
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return []byte(s[:n])
+}
+
+// write title, caption and keywords to IPTC resource of APP13 segment, add segment if there is none
+func updateJpegIPTC(data []byte, title, caption string, keywords []string) ([]byte, error) {
+	segs, rest, err := splitJpeg(data)
+	if err != nil {
+		return nil, err
+	}
+	resources := []photoshopResource{}
+	i := findSegment(segs, markerAPP13, photoshopPrefix)
+	if i >= 0 {
+		resources = parsePhotoshopResources(segs[i][4+len(photoshopPrefix):])
+	}
+	found := false
+	for k, r := range resources {
+		if r.ID == resourceIPTC {
+			records, tail := parseIPTCRecords(r.Data)
+			resources[k].Data = encodeIPTCRecords(setIPTCRecords(records, title, caption, keywords), tail)
+			found = true
+		}
+	}
+	if !found {
+		resources = append(resources, photoshopResource{ID: resourceIPTC, Data: encodeIPTCRecords(setIPTCRecords(nil, title, caption, keywords), nil)})
+	}
+	payload := append(append([]byte{}, photoshopPrefix...), encodePhotoshopResources(resources)...)
+	if len(payload)+2 > 0xffff {
+		return nil, errIPTCTooLong
+	}
+	seg := newSegment(markerAPP13, payload)
+	if i >= 0 {
+		segs[i] = seg
+	} else {
+		segs = insertSegment(segs, seg)
+	}
+	return joinJpeg(segs, rest), nil
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"reflect"
+	"testing"
+)
+
+// IIM dataset with standard length
+func iimDataset(record, dataset byte, value []byte) []byte {
+	b := []byte{0x1c, record, dataset, 0, 0}
+	binary.BigEndian.PutUint16(b[3:], uint16(len(value)))
+	return append(b, value...)
+}
+
+// IIM dataset with extended length of n bytes
+func iimExtendedDataset(record, dataset byte, n int, value []byte) []byte {
+	b := []byte{0x1c, record, dataset, 0x80, byte(n)}
+	size := make([]byte, 4)
+	binary.BigEndian.PutUint32(size, uint32(len(value)))
+	return append(append(b, size[4-n:]...), value...)
+}
+
+func concat(parts ...[]byte) []byte {
+	return bytes.Join(parts, nil)
+}
+
+func TestParseIPTCRecords(t *testing.T) {
+	preview := bytes.Repeat([]byte{0xff, 0xd8, 0x80}, 20000)
+	tests := []struct {
+		name    string
+		data    []byte
+		records []iptcRecord
+		tail    []byte
+	}{
+		{"empty", nil, []iptcRecord{}, nil},
+		{
+			"datasets",
+			concat(iimDataset(2, 0, []byte{0, 4}), iimDataset(2, 25, []byte("sea"))),
+			[]iptcRecord{{2, 0, []byte{0, 4}}, {2, 25, []byte("sea")}},
+			[]byte{},
+		},
+		{
+			"extended",
+			concat(iimExtendedDataset(2, 202, 4, preview), iimExtendedDataset(2, 120, 2, []byte("caption"))),
+			[]iptcRecord{{2, 202, preview}, {2, 120, []byte("caption")}},
+			[]byte{},
+		},
+		{
+			"truncated",
+			concat(iimDataset(2, 5, []byte("title")), iimDataset(2, 120, []byte("caption"))[:8]),
+			[]iptcRecord{{2, 5, []byte("title")}},
+			iimDataset(2, 120, []byte("caption"))[:8],
+		},
+		{
+			"invalid extended length",
+			concat(iimDataset(2, 5, []byte("title")), []byte{0x1c, 2, 202, 0x80, 5, 0, 0, 0, 0, 1, 0xaa}),
+			[]iptcRecord{{2, 5, []byte("title")}},
+			[]byte{0x1c, 2, 202, 0x80, 5, 0, 0, 0, 0, 1, 0xaa},
+		},
+		{
+			"padding",
+			concat(iimDataset(2, 5, []byte("title")), []byte{0, 0, 0}),
+			[]iptcRecord{{2, 5, []byte("title")}},
+			[]byte{0, 0, 0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records, tail := parseIPTCRecords(tt.data)
+			if !reflect.DeepEqual(records, tt.records) {
+				t.Errorf("records = %v, want %v", records, tt.records)
+			}
+			if !bytes.Equal(tail, tt.tail) {
+				t.Errorf("tail = %q, want %q", tail, tt.tail)
+			}
+			// datasets are written back with their values and the tail as is
+			again, tail := parseIPTCRecords(encodeIPTCRecords(records, tail))
+			if !reflect.DeepEqual(again, tt.records) || !bytes.Equal(tail, tt.tail) {
+				t.Errorf("encoded records = %v, tail %q, want %v, %q", again, tail, tt.records, tt.tail)
+			}
+		})
+	}
+}
+
+func TestSetIPTCRecords(t *testing.T) {
+	raster := bytes.Repeat([]byte{0x00, 0xe9, 0xff}, 100)
+	preview := bytes.Repeat([]byte{0xff, 0xd8, 0x80}, 20000)
+	tests := []struct {
+		name    string
+		records []iptcRecord
+		want    []iptcRecord
+	}{
+		{
+			"new",
+			nil,
+			[]iptcRecord{
+				{1, 90, iptcUTF8},
+				{2, 0, []byte{0, 4}},
+				{2, 5, []byte("Title")},
+				{2, 25, []byte("café")},
+				{2, 25, []byte("sea")},
+				{2, 120, []byte("Caption")},
+			},
+		},
+		{
+			"Latin-1 text is transcoded, binary kept",
+			[]iptcRecord{
+				{1, 90, []byte("\x1b(B")},
+				{2, 0, []byte{0, 2}},
+				{2, 5, []byte("old")},
+				{2, 25, []byte("old")},
+				{2, 90, []byte("M\xfcnchen")},
+				{2, 125, raster},
+				{2, 202, preview},
+				{2, 231, []byte("\xe9")},
+			},
+			[]iptcRecord{
+				{1, 90, iptcUTF8},
+				{2, 0, []byte{0, 2}},
+				{2, 90, []byte("München")},
+				{2, 125, raster},
+				{2, 202, preview},
+				{2, 231, []byte("\xe9")},
+				{2, 5, []byte("Title")},
+				{2, 25, []byte("café")},
+				{2, 25, []byte("sea")},
+				{2, 120, []byte("Caption")},
+			},
+		},
+		{
+			"UTF-8 text kept",
+			[]iptcRecord{
+				{2, 90, []byte("München")},
+				{1, 90, iptcUTF8},
+			},
+			[]iptcRecord{
+				{1, 90, iptcUTF8},
+				{2, 0, []byte{0, 4}},
+				{2, 90, []byte("München")},
+				{2, 5, []byte("Title")},
+				{2, 25, []byte("café")},
+				{2, 25, []byte("sea")},
+				{2, 120, []byte("Caption")},
+			},
+		},
+		{
+			"undeclared Latin-1 text is transcoded",
+			[]iptcRecord{
+				{2, 90, []byte("M\xfcnchen")},
+				{2, 101, []byte("Deutschland")},
+			},
+			[]iptcRecord{
+				{1, 90, iptcUTF8},
+				{2, 0, []byte{0, 4}},
+				{2, 90, []byte("München")},
+				{2, 101, []byte("Deutschland")},
+				{2, 5, []byte("Title")},
+				{2, 25, []byte("café")},
+				{2, 25, []byte("sea")},
+				{2, 120, []byte("Caption")},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := setIPTCRecords(tt.records, "Title", "Caption", []string{"café", "sea"})
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("records = %v, want %v", got, tt.want)
+			}
+			tail := []byte{0x1c, 2, 202, 0x80, 0}
+			records, rest := parseIPTCRecords(encodeIPTCRecords(got, tail))
+			if !reflect.DeepEqual(records, tt.want) || !bytes.Equal(rest, tail) {
+				t.Errorf("encoded records = %v, tail %q, want %v, %q", records, rest, tt.want, tail)
+			}
+			charset := iptcCharsetOf(records)
+			for _, r := range records {
+				if r.Record == 2 && r.Dataset == 90 && r.text(charset) != "München" {
+					t.Errorf("city = %q, want München", r.text(charset))
+				}
+			}
+		})
+	}
+}
+
+func TestCutUTF8(t *testing.T) {
+	tests := []struct {
+		s    string
+		n    int
+		want string
+	}{
+		{"sea", 64, "sea"},
+		{"sea", 2, "se"},
+		{"café", 4, "caf"},
+		{"café", 5, "café"},
+		{"", 3, ""},
+	}
+	for _, tt := range tests {
+		if got := string(cutUTF8(tt.s, tt.n)); got != tt.want {
+			t.Errorf("cutUTF8(%q, %d) = %q, want %q", tt.s, tt.n, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,295 @@
+package main
+
+import (
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+const prefKeywords = "keywords"
+
+const nsDC = "http://purl.org/dc/elements/1.1/"
+
+var (
+	xmpSubject     = XMPName{"dc", nsDC, "subject"}
+	xmpTitle       = XMPName{"dc", nsDC, "title"}
+	xmpDescription = XMPName{"dc", nsDC, "description"}
+)
+
+// read title, caption and keywords from embedded XMP, from IPTC if XMP has none
+func (p *Photo) readDescription() {
+	segs, err := readJpegSegments(p.File)
+	if err != nil {
+		return
+	}
+	if i := findSegment(segs, markerAPP1, xmpPrefix); i >= 0 {
+		values, _ := xmpProperties(segs[i][4+len(xmpPrefix):])
+		if p.setXMPDescription(values) {
+			return
+		}
+	}
+	if i := findSegment(segs, markerAPP13, photoshopPrefix); i >= 0 {
+		records := parseIPTC(segs[i][4+len(photoshopPrefix):])
+		charset := iptcCharsetOf(records)
+		for _, r := range records {
+			if r.Record != 2 {
+				continue
+			}
+			switch r.Dataset {
+			case iptcObjectName:
+				p.Title = r.text(charset)
+			case iptcCaptionAbstract:
+				p.Caption = r.text(charset)
+			case iptcKeywords:
+				p.Keywords = append(p.Keywords, r.text(charset))
+			}
+		}
+	}
+}
+
+// set description from XMP properties, false if there is none
+func (p *Photo) setXMPDescription(values map[xml.Name][]string) bool {
+	title, caption, keywords := values[xmpTitle.xmlName()], values[xmpDescription.xmlName()], values[xmpSubject.xmlName()]
+	if len(title)+len(caption)+len(keywords) == 0 {
+		return false
+	}
+	p.Title, p.Caption = "", ""
+	if len(title) > 0 {
+		p.Title = title[0]
+	}
+	if len(caption) > 0 {
+		p.Caption = caption[0]
+	}
+	p.Keywords = append([]string{}, keywords...)
+	return true
+}
+
+// set description properties of XMP packet
+func (p *Photo) editXMPDescription(packet string) string {
+	packet = xmpSetArray(packet, xmpSubject, "Bag", p.Keywords)
+	packet = xmpSetArray(packet, xmpTitle, "Alt", nonEmpty(p.Title))
+	return xmpSetArray(packet, xmpDescription, "Alt", nonEmpty(p.Caption))
+}
+
+func nonEmpty(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return []string{s}
+}
+
+// write title, caption and keywords to XMP and IPTC of photo file,
+// file is moved to backup first unless backedUp tells it was done already
+func updateDescription(file, backupDirName string, backedUp bool, p *Photo) error {
+	src := file
+	if !backedUp {
+		src = filepath.Join(backupDirName, filepath.Base(file))
+		if err := os.Rename(file, src); err != nil {
+			return err
+		}
+	}
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	data, err = updateJpegXMP(data, p.editXMPDescription)
+	if err != nil {
+		return err
+	}
+	data, err = updateJpegIPTC(data, p.Title, p.Caption, p.Keywords)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(file, data, 0664)
+}
+
+// split comma separated keywords, drop empty and duplicate ones
+func splitKeywords(text string) []string {
+	keywords := []string{}
+	seen := map[string]bool{}
+	for _, k := range strings.Split(text, ",") {
+		k = strings.TrimSpace(k)
+		if k != "" && !seen[k] {
+			seen[k] = true
+			keywords = append(keywords, k)
+		}
+	}
+	return keywords
+}
+
+// previously used and current photo keywords
+func (l *PhotoList) knownKeywords() []string {
+	seen := map[string]bool{}
+	keywords := []string{}
+	add := func(k string) {
+		if k != "" && !seen[k] {
+			seen[k] = true
+			keywords = append(keywords, k)
+		}
+	}
+	for _, k := range strings.Split(fyne.CurrentApp().Preferences().String(prefKeywords), "\n") {
+		add(k)
+	}
+	for _, p := range l.List {
+		for _, k := range p.Keywords {
+			add(k)
+		}
+	}
+	sort.Strings(keywords)
+	return keywords
+}
+
+// remember keywords for autocompletion
+func rememberKeywords(keywords []string) {
+	prefs := fyne.CurrentApp().Preferences()
+	known := strings.Split(prefs.String(prefKeywords), "\n")
+	for _, k := range keywords {
+		found := false
+		for _, kk := range known {
+			found = found || kk == k
+		}
+		if !found {
+			known = append(known, k)
+		}
+	}
+	prefs.SetString(prefKeywords, strings.TrimSpace(strings.Join(known, "\n")))
+}
+
+// comma separated keywords entry completing the last keyword from known ones
+func newKeywordsEntry(known []string) *widget.SelectEntry {
+	e := widget.NewSelectEntry(nil)
+	e.SetPlaceHolder("Comma separated keywords")
+	e.OnChanged = func(text string) {
+		head, last := "", text
+		if i := strings.LastIndex(text, ","); i >= 0 {
+			head, last = text[:i+1]+" ", text[i+1:]
+		}
+		last = strings.ToLower(strings.TrimSpace(last))
+		options := []string{}
+		for _, k := range known {
+			if last != "" && strings.HasPrefix(strings.ToLower(k), last) && !strings.EqualFold(k, last) {
+				options = append(options, strings.TrimLeft(head, " ")+k)
+			}
+		}
+		e.SetOptions(options)
+	}
+	return e
+}
+
+// show title, caption and keywords dialog of the photo
+func (l *PhotoList) describePhoto(p *Photo) {
+	title := widget.NewEntry()
+	title.SetText(p.Title)
+	caption := widget.NewMultiLineEntry()
+	caption.SetText(p.Caption)
+	caption.SetMinRowsVisible(3)
+	keywords := newKeywordsEntry(l.knownKeywords())
+	keywords.SetText(strings.Join(p.Keywords, ", "))
+	items := []*widget.FormItem{
+		widget.NewFormItem("Title", title),
+		widget.NewFormItem("Caption", caption),
+		widget.NewFormItem("Keywords", keywords),
+	}
+	dlg := dialog.NewForm(filepath.Base(p.File), "Apply", "Cancel", items, func(ok bool) {
+		if !ok {
+			return
+		}
+		p.Title = strings.TrimSpace(title.Text)
+		p.Caption = strings.TrimSpace(caption.Text)
+		p.Keywords = splitKeywords(keywords.Text)
+		p.descriptionChanged = true
+		rememberKeywords(p.Keywords)
+		l.refresh()
+	}, wMain)
+	dlg.Resize(fyne.NewSize(520, 0))
+	dlg.Show()
+}
+
+// show bulk keywords dialog: keywords are added to or removed from photos, non-empty title and caption replace existing ones
+func (l *PhotoList) describePhotos() {
+	known := l.knownKeywords()
+	scope := widget.NewRadioGroup([]string{ScopeAll, ScopeSelection, ScopeCamera}, nil)
+	scope.Horizontal = true
+	camera := widget.NewSelect(l.cameras(), nil)
+	camera.Disable()
+	scope.OnChanged = func(s string) {
+		if s == ScopeCamera {
+			camera.Enable()
+		} else {
+			camera.Disable()
+		}
+	}
+	scope.SetSelected(ScopeSelection)
+	add := newKeywordsEntry(known)
+	remove := newKeywordsEntry(known)
+	title := widget.NewEntry()
+	title.SetPlaceHolder("Keep existing")
+	caption := widget.NewMultiLineEntry()
+	caption.SetPlaceHolder("Keep existing")
+	caption.SetMinRowsVisible(3)
+	items := []*widget.FormItem{
+		widget.NewFormItem("Photos", scope),
+		widget.NewFormItem("Camera", camera),
+		widget.NewFormItem("Add keywords", add),
+		widget.NewFormItem("Remove keywords", remove),
+		widget.NewFormItem("Title", title),
+		widget.NewFormItem("Caption", caption),
+	}
+	dlg := dialog.NewForm("Keywords and captions", "Apply", "Cancel", items, func(ok bool) {
+		if !ok {
+			return
+		}
+		photos := l.List
+		switch scope.Selected {
+		case ScopeSelection:
+			photos = l.scopeSelection()
+		case ScopeCamera:
+			photos = nil
+			if camera.Selected != "" {
+				photos = l.cameraPhotos(camera.Selected)
+			}
+		}
+		added, removed := splitKeywords(add.Text), splitKeywords(remove.Text)
+		for _, p := range photos {
+			if p.Droped {
+				continue
+			}
+			keywords := []string{}
+			for _, k := range append(p.Keywords, added...) {
+				drop := false
+				for _, r := range removed {
+					drop = drop || strings.EqualFold(k, r)
+				}
+				if !drop {
+					keywords = append(keywords, k)
+				}
+			}
+			p.Keywords = splitKeywords(strings.Join(keywords, ","))
+			if t := strings.TrimSpace(title.Text); t != "" {
+				p.Title = t
+			}
+			if c := strings.TrimSpace(caption.Text); c != "" {
+				p.Caption = c
+			}
+			p.descriptionChanged = true
+		}
+		rememberKeywords(added)
+		l.refresh()
+	}, wMain)
+	dlg.Resize(fyne.NewSize(600, 0))
+	dlg.Show()
+}
+
+// frame column button opening description dialog
+func (p *Photo) describeButton() *widget.Button {
+	btn := widget.NewButtonWithIcon("", theme.DocumentCreateIcon(), func() { pl.describePhoto(p) })
+	btn.Importance = widget.LowImportance
+	return btn
+}
@@ -1,19 +1,20 @@
 package main
 
 import (
-	"errors"
 	"image/color"
-	"io/fs"
 	"log"
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
+	"sync/atomic"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/canvas"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/driver/desktop"
 	"fyne.io/fyne/v2/layout"
 	"fyne.io/fyne/v2/storage"
 	"fyne.io/fyne/v2/theme"
@@ -36,10 +37,31 @@ const (
 type PhotoList struct {
 	Folder    string
 	List      []*Photo
+	View      []*Photo // filtered List photos paged in Choice tab and shown in List tab
 	Order     func(i, j int) bool
 	Frame     *fyne.Container
 	FrameSize int
 	FramePos  int
+
+	table        *widget.Table
+	header       *widget.Table
+	sortKeys     []sortKey
+	pending      map[*Photo]Date     // previewed entered dates
+	pendingGPS   map[*Photo]GeoPoint // previewed positions
+	applyPending func()
+	previewBar   *fyne.Container
+	previewLabel *widget.Label
+	inspector    *metaInspector
+	mapView      *MapView
+	tabs         *container.AppTabs
+	filter       photoFilter
+	filterCount  *widget.Label
+	rowSelection map[*Photo]bool // selected List tab rows
+	rowAnchor    *Photo          // last tapped List tab row
+	columns      []int           // List tab columns shown
+	thumbs       *thumbCache
+	thumbsLoaded atomic.Bool // table refresh for loaded thumbnails is scheduled
+	preview      *rowPreview
 }
 
 // create new PhotoList object for the folder
@@ -50,6 +72,8 @@ func newPhotoList(folder string) *PhotoList {
 		log.Fatalf("Can't list photo files from folder \"%s\". Error: %v\n", folder, err)
 	}
 	photos := []*Photo(nil)
+	patterns := compileNamePatterns()
+	bases := baseNameCounts(files)
 	for _, f := range files {
 		fName := strings.ToLower(f.Name())
 		if strings.HasSuffix(fName, ".jpg") || strings.HasSuffix(fName, ".jpeg") {
@@ -57,12 +81,26 @@ func newPhotoList(folder string) *PhotoList {
 				File:       filepath.Join(folder, f.Name()),
 				Droped:     false,
 				DateChoice: ChoiceExifDate,
-				Dates:      [3]string{},
+				Dates:      [4]Date{},
+				sharedBase: bases[strings.ToLower(strings.TrimSuffix(f.Name(), filepath.Ext(f.Name())))] > 1,
+			}
+			fileExif, _ := getJpegExif(photo.File)
+			photo.Dates[ChoiceExifDate], photo.ExifDateSource = getExifDate(fileExif)
+			photo.exifOffset = photo.Dates[ChoiceExifDate].HasOffset
+			photo.Camera = getCamera(fileExif)
+			photo.CameraSerial = getCameraSerial(fileExif)
+			photo.Shooting = getShootingInfo(fileExif)
+			if fileExif != nil {
+				if lat, long, ok := fileExif.LatLong(); ok {
+					photo.GPS = &GeoPoint{Lat: lat, Long: long}
+				}
 			}
-			photo.Dates[ChoiceExifDate] = getExifDate(photo.File)
 			photo.Dates[ChoiceFileDate] = photo.getModifyDate()
-			if len(photo.Dates[ChoiceExifDate]) != len(DateFormat) {
-				photo.DateChoice = ChoiceFileDate
+			photo.Dates[ChoiceNameDate] = getNameDate(photo.File, patterns)
+			photo.DateChoice = photo.defaultDateChoice()
+			photo.readDescription()
+			if sidecarModePref() {
+				photo.readSidecar()
 			}
 			photos = append(photos, photo)
 		}
@@ -74,6 +112,7 @@ func newPhotoList(folder string) *PhotoList {
 		FramePos:  InitListPos,
 	}
 	l.Order = l.orderByFileNameAsc
+	l.applyZones()
 	return l
 }
 
@@ -81,60 +120,44 @@ func newPhotoList(folder string) *PhotoList {
 func MainLayout(l *PhotoList) {
 	l.reorder(l.Order)
 	l.initFrame()
-	contentTabs := container.NewAppTabs(l.newChoiceTab(), l.newListTab())
+	mapTab := l.newMapTab()
+	contentTabs := container.NewAppTabs(l.newChoiceTab(), l.newListTab(), mapTab)
 	contentTabs.SetTabLocation(container.TabLocationBottom)
+	contentTabs.OnSelected = func(t *container.TabItem) {
+		if t == mapTab {
+			l.showMap()
+		}
+	}
+	l.tabs = contentTabs
 	wMain.SetContent(contentTabs)
 }
 
-// Save choosed photos:
-// 1. move dropped photo to droppped folder
-// 2. update exif dates with file modify date or input date
-func (l *PhotoList) savePhotoList() {
-	dialog.ShowConfirm("Ready to save changes", "Proceed?",
-		func(b bool) {
-			if b {
-				dropDirOk := false
-				dropDirName := filepath.Join(l.Folder, "dropped")
-				backupDirOk := false
-				backupDirName := filepath.Join(l.Folder, "original")
-				for _, p := range l.List {
-					if p.Droped {
-						// move file to drop dir
-						if !dropDirOk {
-							err := os.Mkdir(dropDirName, 0775)
-							if err != nil && !errors.Is(err, fs.ErrExist) {
-								dialog.ShowError(err, wMain)
-							}
-						}
-						os.Rename(p.File, filepath.Join(dropDirName, filepath.Base(p.File)))
-						continue
-					}
-					if p.DateChoice != ChoiceExifDate {
-						// backup original file and make file copy with modified exif
-						if !backupDirOk {
-							err := os.Mkdir(backupDirName, 0775)
-							if err != nil && !errors.Is(err, fs.ErrExist) {
-								dialog.ShowError(err, wMain)
-							}
-						}
-						updateExifDate(p.File, backupDirName, p.Dates[p.DateChoice])
-					}
-				}
-			}
-		},
-		wMain)
-}
-
 // create new photos tab container
 func (l *PhotoList) newListTab() *container.TabItem {
 	toolBar := widget.NewToolbar(
 		widget.NewToolbarAction(theme.FolderOpenIcon(), chooseFolder),
 		widget.NewToolbarAction(theme.DocumentSaveIcon(), l.savePhotoList),
+		widget.NewToolbarSeparator(),
+		widget.NewToolbarAction(theme.HistoryIcon(), l.shiftDates),
+		widget.NewToolbarAction(theme.ViewRefreshIcon(), l.syncCameras),
+		widget.NewToolbarAction(theme.HomeIcon(), l.chooseZones),
+		widget.NewToolbarAction(theme.FileImageIcon(), l.fixFileTimes),
+		widget.NewToolbarAction(theme.MoreHorizontalIcon(), l.interpolateDates),
+		widget.NewToolbarAction(theme.DocumentCreateIcon(), l.describePhotos),
+		widget.NewToolbarAction(theme.MailAttachmentIcon(), l.geotagPhotos),
+		widget.NewToolbarAction(theme.VisibilityOffIcon(), l.privacyPreview),
+		widget.NewToolbarAction(theme.MediaPhotoIcon(), l.togglePreview),
+		widget.NewToolbarSeparator(),
+		widget.NewToolbarAction(theme.UploadIcon(), l.exportList),
+		widget.NewToolbarAction(theme.DownloadIcon(), l.importList),
 		widget.NewToolbarSpacer(),
 		widget.NewToolbarAction(theme.SettingsIcon(), settingsScreen),
 		widget.NewToolbarAction(theme.HelpIcon(), aboutScreen),
 	)
-	return container.NewTabItemWithIcon("List", theme.ListIcon(), container.NewBorder(toolBar, nil, nil, nil, l.newListTabTable()))
+	l.thumbs = newThumbCache(thumbSize, 0)
+	l.preview = newRowPreview()
+	top := container.NewVBox(toolBar, l.newPreviewBar(), l.newFilterBar())
+	return container.NewTabItemWithIcon("List", theme.ListIcon(), container.NewBorder(top, nil, nil, l.preview.panel, l.newListTabTable()))
 }
 
 const (
@@ -149,10 +172,13 @@ type ActiveHeader struct {
 	widget.Label
 	Sortable bool
 	Order    int
-	OnTapped func()
+	Priority int              // sort key number when sorted by several columns, 0 otherwise
+	OnTapped func(shift bool) // shift is true if Shift key was held on tap
+
+	shift bool
 }
 
-func newActiveHeader(label string, tapped func()) *ActiveHeader {
+func newActiveHeader(label string, tapped func(shift bool)) *ActiveHeader {
 	h := &ActiveHeader{
 		Sortable: false,
 		Order:    0,
@@ -164,66 +190,164 @@ func newActiveHeader(label string, tapped func()) *ActiveHeader {
 }
 
 func (h *ActiveHeader) SetText(label string) {
-	h.Label.SetText(label + orderSymbols[h.Order])
+	if h.Priority > 0 {
+		label += orderSymbols[h.Order] + strconv.Itoa(h.Priority)
+	} else {
+		label += orderSymbols[h.Order]
+	}
+	h.Label.SetText(label)
 }
 
 func (h *ActiveHeader) Tapped(_ *fyne.PointEvent) {
 	if h.OnTapped != nil {
-		h.OnTapped()
+		h.OnTapped(h.shift)
 	}
 }
 
+func (h *ActiveHeader) MouseDown(e *desktop.MouseEvent) {
+	h.shift = e.Modifier&fyne.KeyModifierShift != 0
+}
+
+func (h *ActiveHeader) MouseUp(_ *desktop.MouseEvent) {
+}
+
 func (h *ActiveHeader) TappedSecondary(_ *fyne.PointEvent) {
 }
 
+// List tab columns
+const (
+	colFileName = iota
+	colExifDate
+	colExifSource
+	colFileDate
+	colNameDate
+	colEnteredDate
+	colChosenDate
+	colDropped
+	colKeywords
+	colGPS
+	colThumbnail
+)
+
+// date choice shown in list column or -1 if the column is not a date
+func columnDateChoice(col int) int {
+	switch col {
+	case colExifDate:
+		return ChoiceExifDate
+	case colFileDate:
+		return ChoiceFileDate
+	case colNameDate:
+		return ChoiceNameDate
+	case colEnteredDate:
+		return ChoiceEnteredDate
+	}
+	return -1
+}
+
 func (l *PhotoList) newListTabTable() *fyne.Container {
-	listTitle := []string{"File Name", "Exif Date", "File Date", "Entered Date", "Dropped"}
+	listTitle := []string{"File Name", "Exif Date", "Exif Source", "File Date", "Name Date", "Entered Date", "Chosen Date", "Dropped", "Keywords", "GPS", ""}
 
 	table := widget.NewTable(
 		func() (int, int) {
-			return len(l.List), len(listTitle)
+			return len(l.View), len(l.columns)
 		},
 		func() fyne.CanvasObject {
-			text := DateFormat
+			text := DateTemplate
 			for _, ph := range l.List {
 				fName := filepath.Base(ph.File)
 				if len(fName) > len(text) {
 					text = fName
 				}
 			}
-			data := widget.NewLabel(text)
-			return data
+			return newListCell(text, listThumbnailsPref())
 		},
 		func(i widget.TableCellID, o fyne.CanvasObject) {
 			text := ""
-			ph := l.List[i.Row]
-			data := o.(*widget.Label)
-			switch i.Col {
-			case 0:
+			ph := l.View[i.Row]
+			col := l.columns[i.Col]
+			cell := o.(*listCell)
+			cell.setSelected(l.rowSelection[ph])
+			cell.setKind(col)
+			cell.OnTapped = func(shift bool) {
+				l.tapCell(ph, col, shift)
+			}
+			cell.OnHovered = func() {
+				l.preview.show(ph)
+			}
+			cell.OnDoubleTapped = func() {
+				l.showPhoto(ph)
+			}
+			data := cell.label
+			data.TextStyle.Italic = false
+			switch col {
+			case colThumbnail:
+				cell.setThumbnail(l.thumbs.get(ph.File, l.thumbLoaded))
+			case colFileName:
 				text = filepath.Base(ph.File)
 				data.TextStyle.Bold = false
-			case 1, 2, 3:
-				text = ph.Dates[i.Col-1]
-				if i.Col-1 == ph.DateChoice {
+			case colExifDate, colFileDate, colNameDate, colEnteredDate:
+				choice := columnDateChoice(col)
+				text = ph.Dates[choice].String()
+				if d, ok := l.pending[ph]; ok && choice == ChoiceEnteredDate {
+					text = d.String()
+					data.TextStyle.Italic = true
+				}
+				if choice == ph.DateChoice {
 					data.TextStyle.Bold = true
 				} else {
 					data.TextStyle.Bold = false
 				}
-			case 4:
+				if choice == ChoiceEnteredDate {
+					cell.entry.TextStyle = data.TextStyle
+					if ph.DateInvalid {
+						text = ph.invalidDate
+					}
+					// recycled cell being edited keeps text and callbacks of photo the editing started for
+					if wMain.Canvas().Focused() != cell.entry {
+						cell.entry.SetText(text)
+						cell.entry.OnSubmitted = func(s string) {
+							l.enterDate(ph, strings.TrimSpace(s))
+						}
+						cell.entry.OnFocusLost = func() {
+							if s := strings.TrimSpace(cell.entry.Text); s != text {
+								l.enterDate(ph, s)
+							}
+						}
+					}
+				}
+			case colChosenDate:
+				text = ph.Dates[ph.DateChoice].String()
+				data.TextStyle.Bold = false
+			case colExifSource:
+				text = ph.ExifDateSource
+				data.TextStyle.Bold = false
+			case colDropped:
 				if ph.Droped {
 					text = "Yes"
 					data.TextStyle.Bold = true
 				}
+			case colKeywords:
+				text = strings.Join(ph.Keywords, ", ")
+				data.TextStyle.Bold = false
+			case colGPS:
+				if g, ok := l.pendingGPS[ph]; ok {
+					text = g.String()
+					data.TextStyle.Italic = true
+				} else if ph.GPS != nil {
+					text = ph.GPS.String()
+				}
+				data.TextStyle.Bold = false
 			}
 			data.SetText(text)
 		})
+	l.table = table
 
 	header := widget.NewTable(
 		func() (int, int) {
-			return 1, len(listTitle)
+			return 1, len(l.columns)
 		},
 		func() fyne.CanvasObject {
-			text := DateFormat
+			text := DateTemplate
 			for _, ph := range l.List {
 				fName := filepath.Base(ph.File)
 				if len(fName) > len(text) {
@@ -235,19 +359,30 @@ func (l *PhotoList) newListTabTable() *fyne.Container {
 		},
 		func(i widget.TableCellID, o fyne.CanvasObject) {
 			h := o.(*ActiveHeader)
-			h.SetText(listTitle[i.Col])
+			col := l.columns[i.Col]
+			h.Sortable = col != colThumbnail
+			h.Order, h.Priority = unordered, 0
+			for k, key := range l.sortKeys {
+				if key.col == col {
+					h.Order = key.order
+					if len(l.sortKeys) > 1 {
+						h.Priority = k + 1
+					}
+				}
+			}
+			if !h.Sortable {
+				h.Label.SetText(listTitle[col])
+				h.OnTapped = nil
+				return
+			}
+			h.SetText(listTitle[col])
 			h.TextStyle.Bold = true
-			// switch i.Col {
-			// case 0:
-			// 	h.OnTapped = func() {
-			// 		if l.Order == l.orderByFileDateAsc {
-			// 			l.reorder(l.orderByFileNameDesc)
-			// 		}
-			// 	}
-			// case 3:
-			// 	h.OnTapped = func() { l.reorder(l.orderByFileDateDesc) }
-			// }
+			h.OnTapped = func(shift bool) {
+				l.sortByColumn(col, shift)
+			}
 		})
+	l.header = header
+	l.updateListColumns()
 	return container.NewBorder(header, nil, nil, nil, table)
 }
 
@@ -256,12 +391,18 @@ func (l *PhotoList) newChoiceTab() *container.TabItem {
 	actOpenFolder := widget.NewToolbarAction(theme.FolderOpenIcon(), chooseFolder)
 	actDecFrame := widget.NewToolbarAction(theme.ContentRemoveIcon(), func() { l.resizeFrame(RemoveColumn) })
 	actIncFrame := widget.NewToolbarAction(theme.ContentAddIcon(), func() { l.resizeFrame(AddColumn) })
+	l.inspector = newMetaInspector()
 	toolBar := widget.NewToolbar(
 		widget.NewToolbarSpacer(),
 		widget.NewToolbarAction(theme.SettingsIcon(), settingsScreen),
 		widget.NewToolbarAction(theme.HelpIcon(), aboutScreen),
 	)
 	if len(l.List) > 0 {
+		toolBar.Prepend(widget.NewToolbarAction(theme.InfoIcon(), l.toggleInspector))
+		toolBar.Prepend(widget.NewToolbarAction(theme.DocumentCreateIcon(), l.describePhotos))
+		toolBar.Prepend(widget.NewToolbarAction(theme.ViewRefreshIcon(), l.syncCameras))
+		toolBar.Prepend(widget.NewToolbarAction(theme.HistoryIcon(), l.shiftDates))
+		toolBar.Prepend(widget.NewToolbarSeparator())
 		toolBar.Prepend(actIncFrame)
 		toolBar.Prepend(actDecFrame)
 	} else {
@@ -285,11 +426,11 @@ func (l *PhotoList) newChoiceTab() *container.TabItem {
 		l.scrollFrame(l.FramePos + l.FrameSize)
 	})
 	lastPhotoBtn := widget.NewButton(">|", func() {
-		l.scrollFrame(len(l.List))
+		l.scrollFrame(len(l.View))
 	})
 	bottomButtons := container.NewGridWithColumns(6, firstPhotoBtn, prevFrameBtn, prevPhotoBtn, nextPhotoBtn, nextFrameBtn, lastPhotoBtn)
 
-	return container.NewTabItemWithIcon("Choice", theme.GridIcon(), container.NewBorder(toolBar, bottomButtons, nil, nil, l.Frame))
+	return container.NewTabItemWithIcon("Choice", theme.GridIcon(), container.NewBorder(toolBar, bottomButtons, nil, l.inspector.panel, l.Frame))
 }
 
 // scroll frame at position pos
@@ -298,35 +439,35 @@ func (l *PhotoList) scrollFrame(pos int) {
 	switch {
 	case pos < 0:
 		pos = 0
-	case pos > len(l.List)-l.FrameSize:
-		pos = len(l.List) - l.FrameSize
+	case pos > len(l.View)-l.FrameSize:
+		pos = len(l.View) - l.FrameSize
 	}
 
 	switch {
 	case pos-l.FramePos >= l.FrameSize || l.FramePos-pos >= l.FrameSize:
 		for i := l.FramePos; i < l.FramePos+l.FrameSize; i++ {
-			l.List[i].Img = nil
+			l.View[i].Img = nil
 		}
 		for i := pos; i < pos+l.FrameSize; i++ {
-			l.List[i].Img = l.List[i].img(l.FrameSize)
-			if l.List[i].Droped {
-				l.List[i].Img.Translucency = 0.5
+			l.View[i].Img = l.View[i].img(l.FrameSize)
+			if l.View[i].Droped {
+				l.View[i].Img.Translucency = 0.5
 			}
 		}
 	case pos > l.FramePos:
 		for i := l.FramePos; i < pos; i++ {
-			l.List[i].Img = nil
-			l.List[i+l.FrameSize].Img = l.List[i+l.FrameSize].img(l.FrameSize)
-			if l.List[i+l.FrameSize].Droped {
-				l.List[i+l.FrameSize].Img.Translucency = 0.5
+			l.View[i].Img = nil
+			l.View[i+l.FrameSize].Img = l.View[i+l.FrameSize].img(l.FrameSize)
+			if l.View[i+l.FrameSize].Droped {
+				l.View[i+l.FrameSize].Img.Translucency = 0.5
 			}
 		}
 	case l.FramePos > pos:
 		for i := pos; i < l.FramePos; i++ {
-			l.List[i+l.FrameSize].Img = nil
-			l.List[i].Img = l.List[i].img(l.FrameSize)
-			if l.List[i].Droped {
-				l.List[i].Img.Translucency = 0.5
+			l.View[i+l.FrameSize].Img = nil
+			l.View[i].Img = l.View[i].img(l.FrameSize)
+			if l.View[i].Droped {
+				l.View[i].Img.Translucency = 0.5
 			}
 		}
 	}
@@ -335,7 +476,7 @@ func (l *PhotoList) scrollFrame(pos int) {
 	// https://stackoverflow.com/questions/63995289/how-to-remove-objects-from-golang-fyne-container
 	l.Frame.RemoveAll()
 	for i := 0; i < l.FrameSize; i++ {
-		l.Frame.Add(l.List[pos+i].FrameColumn())
+		l.Frame.Add(l.View[pos+i].FrameColumn())
 	}
 	l.Frame.Refresh()
 
@@ -350,20 +491,20 @@ func (l *PhotoList) resizeFrame(zoom int) {
 		if l.FrameSize-1 < MinFrameSize {
 			return
 		}
-		l.List[l.FramePos+l.FrameSize-1].Img = nil
+		l.View[l.FramePos+l.FrameSize-1].Img = nil
 		l.FrameSize--
 	case AddColumn:
-		if l.FrameSize+1 > MaxFrameSize || l.FrameSize+1 > len(l.List) {
+		if l.FrameSize+1 > MaxFrameSize || l.FrameSize+1 > len(l.View) {
 			return
 		}
 		i := l.FramePos + l.FrameSize
-		if i == len(l.List) {
+		if i == len(l.View) {
 			l.FramePos--
 			i = l.FramePos
 		}
-		l.List[i].Img = l.List[i].img(l.FrameSize)
-		if l.List[i].Droped {
-			l.List[i].Img.Translucency = 0.5
+		l.View[i].Img = l.View[i].img(l.FrameSize)
+		if l.View[i].Droped {
+			l.View[i].Img.Translucency = 0.5
 		}
 		l.FrameSize++
 	}
@@ -376,27 +517,46 @@ func (l *PhotoList) resizeFrame(zoom int) {
 	// https://stackoverflow.com/questions/63995289/how-to-remove-objects-from-golang-fyne-container
 	l.Frame.RemoveAll()
 	for i := 0; i < l.FrameSize; i++ {
-		l.Frame.Add(l.List[l.FramePos+i].FrameColumn())
+		l.Frame.Add(l.View[l.FramePos+i].FrameColumn())
 	}
 	l.Frame.Layout = layout.NewGridLayoutWithColumns(len(l.Frame.Objects))
 	l.Frame.Refresh()
 }
 
+// rebuild frame columns and list table after photo data change
+func (l *PhotoList) refresh() {
+	if l.FrameSize > 0 {
+		l.Frame.RemoveAll()
+		for i := 0; i < l.FrameSize; i++ {
+			l.Frame.Add(l.View[l.FramePos+i].FrameColumn())
+		}
+		l.Frame.Refresh()
+	}
+	if l.table != nil {
+		l.table.Refresh()
+	}
+}
+
+// photos shown in the frame
+func (l *PhotoList) selection() []*Photo {
+	return l.View[l.FramePos : l.FramePos+l.FrameSize]
+}
+
 // fill frame Num photo images starting with Pos = 0.
 func (l *PhotoList) initFrame() {
-	if l.FrameSize > len(l.List) {
-		l.FrameSize = len(l.List)
+	if l.FrameSize > len(l.View) {
+		l.FrameSize = len(l.View)
 	}
 	if l.FrameSize == 0 { // Workaround for NewGridWithColumns(0) main window shrink on Windows OS
 		l.Frame = container.NewGridWithColumns(1, canvas.NewText("", color.Black))
 		return
 	}
-	for i := l.FramePos; i < l.FramePos+l.FrameSize && i < len(l.List); i++ {
-		l.List[i].Img = l.List[i].img(l.FrameSize)
+	for i := l.FramePos; i < l.FramePos+l.FrameSize && i < len(l.View); i++ {
+		l.View[i].Img = l.View[i].img(l.FrameSize)
 	}
 	l.Frame = container.NewGridWithColumns(l.FrameSize)
-	for i := 0; i < l.FrameSize && i < len(l.List); i++ {
-		l.Frame.Add(l.List[l.FramePos+i].FrameColumn())
+	for i := 0; i < l.FrameSize && i < len(l.View); i++ {
+		l.Frame.Add(l.View[l.FramePos+i].FrameColumn())
 	}
 }
 
@@ -428,20 +588,149 @@ func chooseFolder() {
 
 func (l *PhotoList) reorder(less func(i int, j int) bool) {
 	sort.Slice(l.List, less)
+	l.View = l.filter.apply(l.List)
 }
 
 func (l *PhotoList) orderByFileNameAsc(i, j int) bool {
 	return l.List[i].File < l.List[j].File
 }
 
-func (l *PhotoList) orderByFileNameDesc(i, j int) bool {
-	return l.List[j].File < l.List[i].File
+// List tab sort key
+type sortKey struct {
+	col   int
+	order int
+}
+
+// cycle column order unordered, ascending, descending; with shift the column is added to sort keys,
+// otherwise it becomes the only key
+func (l *PhotoList) sortByColumn(col int, shift bool) {
+	order := unordered
+	at := -1
+	for k, key := range l.sortKeys {
+		if key.col == col {
+			order, at = key.order, k
+		}
+	}
+	order = (order + 1) % len(orderSymbols)
+	switch {
+	case !shift && order == unordered:
+		l.sortKeys = nil
+	case !shift:
+		l.sortKeys = []sortKey{{col, order}}
+	case at >= 0 && order == unordered:
+		l.sortKeys = append(l.sortKeys[:at], l.sortKeys[at+1:]...)
+	case at >= 0:
+		l.sortKeys[at].order = order
+	default:
+		l.sortKeys = append(l.sortKeys, sortKey{col, order})
+	}
+	l.Order = l.orderByFileNameAsc
+	if len(l.sortKeys) > 0 {
+		l.Order = l.orderBySortKeys
+	}
+	l.applyOrder()
+}
+
+// reorder list and reload Choice frame photos at the same position
+func (l *PhotoList) applyOrder() {
+	for i := l.FramePos; i < l.FramePos+l.FrameSize; i++ {
+		l.View[i].Img = nil
+	}
+	sort.SliceStable(l.List, l.Order)
+	l.View = l.filter.apply(l.List)
+	for i := l.FramePos; i < l.FramePos+l.FrameSize; i++ {
+		l.View[i].Img = l.View[i].img(l.FrameSize)
+		if l.View[i].Droped {
+			l.View[i].Img.Translucency = 0.5
+		}
+	}
+	l.refresh()
+	if l.header != nil {
+		l.header.Refresh()
+	}
+}
+
+func (l *PhotoList) orderBySortKeys(i, j int) bool {
+	for _, key := range l.sortKeys {
+		c := compareColumn(key.col, l.List[i], l.List[j])
+		if key.order == orderDesc {
+			c = -c
+		}
+		if c != 0 {
+			return c < 0
+		}
+	}
+	return l.List[i].File < l.List[j].File
+}
+
+// compare photos by List tab column, empty values go last in ascending order
+func compareColumn(col int, a, b *Photo) int {
+	switch col {
+	case colFileName:
+		return strings.Compare(a.File, b.File)
+	case colExifDate, colFileDate, colNameDate, colEnteredDate:
+		choice := columnDateChoice(col)
+		return compareDates(a.Dates[choice], b.Dates[choice])
+	case colChosenDate:
+		return compareDates(a.Dates[a.DateChoice], b.Dates[b.DateChoice])
+	case colExifSource:
+		return compareText(a.ExifDateSource, b.ExifDateSource)
+	case colDropped:
+		return compareBool(a.Droped, b.Droped)
+	case colKeywords:
+		return compareText(strings.Join(a.Keywords, ", "), strings.Join(b.Keywords, ", "))
+	case colGPS:
+		switch {
+		case a.GPS == nil || b.GPS == nil:
+			return compareBool(a.GPS != nil, b.GPS != nil)
+		case a.GPS.Lat != b.GPS.Lat:
+			return compareFloat(a.GPS.Lat, b.GPS.Lat)
+		}
+		return compareFloat(a.GPS.Long, b.GPS.Long)
+	}
+	return 0
 }
 
-func (l *PhotoList) orderByFileDateAsc(i, j int) bool {
-	return l.List[i].Dates[ChoiceFileDate] < l.List[j].Dates[ChoiceFileDate]
+func compareDates(a, b Date) int {
+	switch {
+	case a.Before(b):
+		return -1
+	case b.Before(a):
+		return 1
+	}
+	return 0
+}
+
+// compare text, empty text goes last
+func compareText(a, b string) int {
+	switch {
+	case a == b:
+		return 0
+	case a == "":
+		return 1
+	case b == "":
+		return -1
+	}
+	return strings.Compare(a, b)
 }
 
-func (l *PhotoList) orderByFileDateDesc(i, j int) bool {
-	return l.List[j].Dates[ChoiceFileDate] < l.List[i].Dates[ChoiceFileDate]
+// true goes first
+func compareBool(a, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case a:
+		return -1
+	}
+	return 1
+}
+
+func compareFloat(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
 }
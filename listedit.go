@@ -0,0 +1,281 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// List tab table cell, label, entered date entry or thumbnail over selection background
+type listCell struct {
+	widget.BaseWidget
+	background *canvas.Rectangle
+	label      *widget.Label
+	entry      *dateCellEntry
+	thumb      *canvas.Image
+
+	OnTapped       func(shift bool) // shift is true if Shift key was held on tap
+	OnDoubleTapped func()
+	OnHovered      func()
+
+	shift    bool
+	thumbRow bool // row height fits thumbnail
+}
+
+// table cell, template cell height defines row height of List tab table
+func newListCell(text string, thumbRow bool) *listCell {
+	c := &listCell{
+		background: canvas.NewRectangle(color.Transparent),
+		label:      widget.NewLabel(text),
+		entry:      newDateCellEntry(),
+		thumb:      canvas.NewImageFromImage(nil),
+	}
+	c.thumb.FillMode = canvas.ImageFillContain
+	c.thumb.SetMinSize(fyne.NewSize(thumbSize, thumbSize))
+	c.thumb.Hide()
+	c.thumbRow = thumbRow
+	c.entry.Validator = func(s string) error {
+		if s == "" {
+			return nil
+		}
+		_, err := parseDate(s)
+		return err
+	}
+	c.entry.Hide()
+	c.ExtendBaseWidget(c)
+	return c
+}
+
+func (c *listCell) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(container.NewMax(c.background, c.label, c.entry, c.thumb))
+}
+
+func (c *listCell) MinSize() fyne.Size {
+	s := c.BaseWidget.MinSize()
+	if c.thumbRow {
+		s.Height = fyne.Max(s.Height, thumbSize)
+	}
+	return s
+}
+
+// entered date entry, typed date is applied on submit and on focus loss
+type dateCellEntry struct {
+	widget.Entry
+	OnFocusLost func()
+}
+
+func newDateCellEntry() *dateCellEntry {
+	e := &dateCellEntry{}
+	e.ExtendBaseWidget(e)
+	return e
+}
+
+func (e *dateCellEntry) FocusLost() {
+	e.Entry.FocusLost()
+	if e.OnFocusLost != nil {
+		e.OnFocusLost()
+	}
+}
+
+// show thumbnail for thumbnail column, entry for entered date and label otherwise
+func (c *listCell) setKind(col int) {
+	c.label.Hide()
+	c.entry.Hide()
+	c.thumb.Hide()
+	switch col {
+	case colThumbnail:
+		c.thumb.Show()
+	case colEnteredDate:
+		c.entry.Show()
+	default:
+		c.label.Show()
+	}
+}
+
+func (c *listCell) setThumbnail(m image.Image) {
+	c.thumb.Image = m
+	c.thumb.Refresh()
+}
+
+func (c *listCell) setSelected(selected bool) {
+	if selected {
+		c.background.FillColor = theme.SelectionColor()
+	} else {
+		c.background.FillColor = color.Transparent
+	}
+	c.background.Refresh()
+}
+
+func (c *listCell) Tapped(_ *fyne.PointEvent) {
+	if c.OnTapped != nil {
+		c.OnTapped(c.shift)
+	}
+}
+
+func (c *listCell) DoubleTapped(_ *fyne.PointEvent) {
+	if c.OnDoubleTapped != nil {
+		c.OnDoubleTapped()
+	}
+}
+
+func (c *listCell) MouseIn(_ *desktop.MouseEvent) {
+	if c.OnHovered != nil {
+		c.OnHovered()
+	}
+}
+
+func (c *listCell) MouseMoved(_ *desktop.MouseEvent) {
+}
+
+func (c *listCell) MouseOut() {
+}
+
+func (c *listCell) MouseDown(e *desktop.MouseEvent) {
+	c.shift = e.Modifier&fyne.KeyModifierShift != 0
+}
+
+func (c *listCell) MouseUp(_ *desktop.MouseEvent) {
+}
+
+// tap on List tab cell: file name selects rows, dropped is toggled and date column becomes chosen
+func (l *PhotoList) tapCell(p *Photo, col int, shift bool) {
+	l.preview.show(p)
+	switch col {
+	case colFileName:
+		l.selectRow(p, shift)
+		return
+	case colDropped:
+		droped := !p.Droped
+		for _, ph := range l.targets(p) {
+			ph.Droped = droped
+			if ph.Img != nil {
+				ph.Img.Translucency = 0
+				if droped {
+					ph.Img.Translucency = 0.5
+				}
+			}
+		}
+	case colExifDate, colFileDate, colNameDate:
+		choice := columnDateChoice(col)
+		for _, ph := range l.targets(p) {
+			if !ph.Dates[choice].IsZero() {
+				ph.DateChoice = choice
+			}
+		}
+	default:
+		return
+	}
+	l.refresh()
+}
+
+// set typed entered date, empty text clears it
+func (l *PhotoList) enterDate(p *Photo, text string) {
+	if text == "" {
+		for _, ph := range l.targets(p) {
+			ph.Dates[ChoiceEnteredDate] = Date{}
+			ph.DateInvalid = false
+			if ph.DateChoice == ChoiceEnteredDate {
+				ph.DateChoice = ph.defaultDateChoice()
+			}
+		}
+		l.refresh()
+		return
+	}
+	d, err := parseDate(text)
+	if err != nil {
+		dialog.ShowError(err, wMain)
+		return
+	}
+	changes := []DateChange{}
+	for _, ph := range l.targets(p) {
+		changes = append(changes, DateChange{Photo: ph, Date: d})
+	}
+	l.applyDateChanges(changes)
+}
+
+// photos changed by edit of photo p: all selected rows if p is selected, p otherwise
+func (l *PhotoList) targets(p *Photo) []*Photo {
+	if !l.rowSelection[p] {
+		return []*Photo{p}
+	}
+	return l.selectedRows()
+}
+
+// toggle row selection, with shift rows from the last tapped one are selected
+func (l *PhotoList) selectRow(p *Photo, shift bool) {
+	if l.rowSelection == nil {
+		l.rowSelection = map[*Photo]bool{}
+	}
+	from, to := -1, -1
+	for i, ph := range l.View {
+		if ph == l.rowAnchor {
+			from = i
+		}
+		if ph == p {
+			to = i
+		}
+	}
+	if shift && from >= 0 && to >= 0 {
+		if from > to {
+			from, to = to, from
+		}
+		for _, ph := range l.View[from : to+1] {
+			l.rowSelection[ph] = true
+		}
+	} else if l.rowSelection[p] {
+		delete(l.rowSelection, p)
+	} else {
+		l.rowSelection[p] = true
+	}
+	l.rowAnchor = p
+	l.updateFilterCount()
+	l.table.Refresh()
+}
+
+// select all List tab rows or none
+func (l *PhotoList) selectAllRows(all bool) {
+	l.rowSelection = map[*Photo]bool{}
+	l.rowAnchor = nil
+	if all {
+		for _, p := range l.View {
+			l.rowSelection[p] = true
+		}
+	}
+	l.updateFilterCount()
+	l.table.Refresh()
+}
+
+// photos of selected List tab rows in list order
+func (l *PhotoList) selectedRows() []*Photo {
+	photos := []*Photo{}
+	for _, p := range l.List {
+		if l.rowSelection[p] {
+			photos = append(photos, p)
+		}
+	}
+	return photos
+}
+
+// photos of Selection scope: selected List tab rows or Choice frame photos if no row is selected
+func (l *PhotoList) scopeSelection() []*Photo {
+	if photos := l.selectedRows(); len(photos) > 0 {
+		return photos
+	}
+	return l.selection()
+}
+
+// List tab row selection count
+func (l *PhotoList) selectionCount() string {
+	if n := len(l.selectedRows()); n > 0 {
+		return fmt.Sprintf(", %d selected", n)
+	}
+	return ""
+}
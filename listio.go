@@ -0,0 +1,292 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+)
+
+// date choice names in exported list
+var dateChoiceNames = map[int]string{
+	ChoiceExifDate:    "EXIF",
+	ChoiceFileDate:    "File",
+	ChoiceEnteredDate: "Entered",
+	ChoiceNameDate:    "Name",
+}
+
+// exported photo list row
+type PhotoRecord struct {
+	File        string   `json:"file"`
+	ExifDate    string   `json:"exifDate"`
+	ExifSource  string   `json:"exifSource"`
+	FileDate    string   `json:"fileDate"`
+	NameDate    string   `json:"nameDate"`
+	EnteredDate string   `json:"enteredDate"`
+	ChosenDate  string   `json:"chosenDate"`
+	DateSource  string   `json:"dateSource"`
+	Dropped     *bool    `json:"dropped"` // nil if imported list has no dropped column
+	Camera      string   `json:"camera"`
+	Serial      string   `json:"serial"`
+	Rating      int      `json:"rating"`
+	Label       string   `json:"label"`
+	Title       string   `json:"title"`
+	Caption     string   `json:"caption"`
+	Keywords    []string `json:"keywords"`
+	GPS         string   `json:"gps"`
+}
+
+// CSV header, column order matches PhotoRecord.values
+var recordColumns = []string{"File", "Exif Date", "Exif Source", "File Date", "Name Date", "Entered Date", "Chosen Date", "Date Source",
+	"Dropped", "Camera", "Serial", "Rating", "Label", "Title", "Caption", "Keywords", "GPS"}
+
+var errNoFileColumn = errors.New("no File column in list")
+
+func newPhotoRecord(p *Photo) PhotoRecord {
+	droped := p.Droped
+	r := PhotoRecord{
+		File:        filepath.Base(p.File),
+		ExifDate:    p.Dates[ChoiceExifDate].String(),
+		ExifSource:  p.ExifDateSource,
+		FileDate:    p.Dates[ChoiceFileDate].String(),
+		NameDate:    p.Dates[ChoiceNameDate].String(),
+		EnteredDate: p.Dates[ChoiceEnteredDate].String(),
+		ChosenDate:  p.Dates[p.DateChoice].String(),
+		DateSource:  dateChoiceNames[p.DateChoice],
+		Dropped:     &droped,
+		Camera:      p.Camera,
+		Serial:      p.CameraSerial,
+		Rating:      p.Rating,
+		Label:       p.Label,
+		Title:       p.Title,
+		Caption:     p.Caption,
+		Keywords:    p.Keywords,
+	}
+	if p.GPS != nil {
+		r.GPS = p.GPS.String()
+	}
+	return r
+}
+
+func (r PhotoRecord) values() []string {
+	dropped := r.Dropped != nil && *r.Dropped
+	return []string{r.File, r.ExifDate, r.ExifSource, r.FileDate, r.NameDate, r.EnteredDate, r.ChosenDate, r.DateSource,
+		strconv.FormatBool(dropped), r.Camera, r.Serial, strconv.Itoa(r.Rating), r.Label, r.Title, r.Caption,
+		strings.Join(r.Keywords, ", "), r.GPS}
+}
+
+// write photo list as JSON if file name ends with .json, as CSV otherwise
+func writePhotoRecords(w io.Writer, name string, records []PhotoRecord) error {
+	if strings.EqualFold(filepath.Ext(name), ".json") {
+		e := json.NewEncoder(w)
+		e.SetIndent("", "  ")
+		return e.Encode(records)
+	}
+	cw := csv.NewWriter(w)
+	cw.Write(recordColumns)
+	for _, r := range records {
+		cw.Write(r.values())
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// read photo list written by writePhotoRecords, only file column is required,
+// entered date, date source and dropped columns are read if present
+func readPhotoRecords(r io.Reader, name string) ([]PhotoRecord, error) {
+	records := []PhotoRecord{}
+	// files saved by Excel start with UTF-8 byte order mark
+	br := bufio.NewReader(r)
+	if bom, err := br.Peek(3); err == nil && string(bom) == "\ufeff" {
+		br.Discard(3)
+	}
+	r = br
+	if strings.EqualFold(filepath.Ext(name), ".json") {
+		err := json.NewDecoder(r).Decode(&records)
+		return records, err
+	}
+	rows, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return records, nil
+	}
+	index := map[string]int{}
+	for i, name := range rows[0] {
+		index[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	value := func(row []string, column string) string {
+		if i, ok := index[strings.ToLower(column)]; ok && i < len(row) {
+			return strings.TrimSpace(row[i])
+		}
+		return ""
+	}
+	if _, ok := index["file"]; !ok {
+		return nil, errNoFileColumn
+	}
+	_, hasDropped := index["dropped"]
+	for _, row := range rows[1:] {
+		r := PhotoRecord{
+			File:        value(row, "File"),
+			EnteredDate: value(row, "Entered Date"),
+			DateSource:  value(row, "Date Source"),
+		}
+		if hasDropped {
+			dropped := parseDropped(value(row, "Dropped"))
+			r.Dropped = &dropped
+		}
+		records = append(records, r)
+	}
+	return records, nil
+}
+
+func parseDropped(s string) bool {
+	if b, err := strconv.ParseBool(s); err == nil {
+		return b
+	}
+	return strings.EqualFold(s, "yes")
+}
+
+// photo list import changes
+type listImport struct {
+	Matched, Unmatched, Dropped int
+	drops                       map[*Photo]bool
+	dates                       []DateChange
+	choices                     map[*Photo]int // chosen date sources
+}
+
+// match imported records with photos by file name
+func (l *PhotoList) matchRecords(records []PhotoRecord) (*listImport, error) {
+	photos := map[string]*Photo{}
+	for _, p := range l.List {
+		photos[filepath.Base(p.File)] = p
+	}
+	m := &listImport{drops: map[*Photo]bool{}, choices: map[*Photo]int{}}
+	for _, r := range records {
+		p, ok := photos[filepath.Base(r.File)]
+		if !ok {
+			m.Unmatched++
+			continue
+		}
+		m.Matched++
+		if r.Dropped != nil {
+			m.drops[p] = *r.Dropped
+			if *r.Dropped {
+				m.Dropped++
+			}
+		}
+		if r.EnteredDate != "" {
+			d, err := parseDate(r.EnteredDate)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", r.File, err)
+			}
+			m.dates = append(m.dates, DateChange{Photo: p, Date: d})
+			m.choices[p] = ChoiceEnteredDate
+		}
+		if choice, ok := parseDateSource(r.DateSource); ok && (!p.Dates[choice].IsZero() || choice == ChoiceEnteredDate && r.EnteredDate != "") {
+			m.choices[p] = choice
+		}
+	}
+	return m, nil
+}
+
+// date choice of exported date source name
+func parseDateSource(s string) (int, bool) {
+	for choice, name := range dateChoiceNames {
+		if strings.EqualFold(s, name) {
+			return choice, true
+		}
+	}
+	return 0, false
+}
+
+// save photo list to CSV or JSON file
+func (l *PhotoList) exportList() {
+	dlg := dialog.NewFileSave(func(w fyne.URIWriteCloser, err error) {
+		if err != nil || w == nil {
+			return
+		}
+		records := []PhotoRecord{}
+		for _, p := range l.List {
+			records = append(records, newPhotoRecord(p))
+		}
+		err = writePhotoRecords(w, w.URI().Name(), records)
+		if cerr := w.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			dialog.ShowError(err, wMain)
+			return
+		}
+		dialog.ShowInformation("Export", fmt.Sprintf("%d photos written to %s", len(records), w.URI().Name()), wMain)
+	}, wMain)
+	dlg.SetFilter(storage.NewExtensionFileFilter([]string{".csv", ".json"}))
+	dlg.SetFileName(filepath.Base(l.Folder) + ".csv")
+	if folder, err := storage.ListerForURI(storage.NewFileURI(l.Folder)); err == nil {
+		dlg.SetLocation(folder)
+	}
+	dlg.Resize(fyne.NewSize(800, 600))
+	dlg.Show()
+}
+
+// apply drop flags and entered dates from CSV or JSON file
+func (l *PhotoList) importList() {
+	dlg := dialog.NewFileOpen(func(r fyne.URIReadCloser, err error) {
+		if err != nil || r == nil {
+			return
+		}
+		records, err := readPhotoRecords(r, r.URI().Name())
+		r.Close()
+		if err != nil {
+			dialog.ShowError(err, wMain)
+			return
+		}
+		m, err := l.matchRecords(records)
+		if err != nil {
+			dialog.ShowError(err, wMain)
+			return
+		}
+		msg := fmt.Sprintf("%d photos matched, %d not found\n%d dropped, %d entered dates", m.Matched, m.Unmatched, m.Dropped, len(m.dates))
+		dialog.ShowConfirm("Import", msg+"\n\nApply?", func(ok bool) {
+			if ok {
+				l.applyImport(m)
+			}
+		}, wMain)
+	}, wMain)
+	dlg.SetFilter(storage.NewExtensionFileFilter([]string{".csv", ".json"}))
+	if folder, err := storage.ListerForURI(storage.NewFileURI(l.Folder)); err == nil {
+		dlg.SetLocation(folder)
+	}
+	dlg.Resize(fyne.NewSize(800, 600))
+	dlg.Show()
+}
+
+func (l *PhotoList) applyImport(m *listImport) {
+	for p, droped := range m.drops {
+		p.Droped = droped
+		if p.Img != nil {
+			p.Img.Translucency = 0
+			if droped {
+				p.Img.Translucency = 0.5
+			}
+		}
+	}
+	for _, c := range m.dates {
+		c.Photo.Dates[ChoiceEnteredDate] = c.Date
+		c.Photo.DateInvalid = false
+	}
+	for p, choice := range m.choices {
+		p.DateChoice = choice
+	}
+	l.refresh()
+}
@@ -0,0 +1,659 @@
+package main
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/png"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// preference keys of offline map sources
+const (
+	prefMapTiles    = "mapTiles"
+	prefMapOutlines = "mapOutlines"
+)
+
+// low resolution world outlines drawn over the grid if no outlines file is set
+//
+//go:embed mapdata/world.geojson
+var worldOutlines []byte
+
+const (
+	tileSize        = 256
+	maxMapZoom      = 18
+	clusterCell     = 48 // marker cluster cell size in pixels
+	markerRadius    = 7
+	clusterRadius   = 12
+	maxMercatorLat  = 85.0511
+	maxCachedTiles  = 256  // tile images kept by map renderer
+	maxMissingTiles = 4096 // tiles remembered as missing by map renderer
+)
+
+// Photo position on map
+type mapMarker struct {
+	photo *Photo
+	lat   float64
+	long  float64
+}
+
+// markers close on screen shown as one
+type mapCluster struct {
+	pos    fyne.Position
+	photos []*Photo
+}
+
+// Offline map of geotagged photos: tiles from local z/x/y directory or MBTiles file or lat/long grid with outlines
+type MapView struct {
+	widget.BaseWidget
+	OnSelected func(p *Photo)
+
+	zoom     int
+	centerX  float64 // center in world pixels at zoom
+	centerY  float64
+	markers  []mapMarker
+	clusters []mapCluster
+	fitted   bool // view was fitted to markers
+	photos   int  // SetPhotos calls, markers are rebuilt when it changes
+}
+
+func newMapView(selected func(p *Photo)) *MapView {
+	m := &MapView{OnSelected: selected, zoom: 1}
+	m.centerX, m.centerY = project(0, 0, m.zoom)
+	m.ExtendBaseWidget(m)
+	return m
+}
+
+// set photos shown as markers, photos without position are skipped
+func (m *MapView) SetPhotos(photos []*Photo) {
+	m.markers = m.markers[:0]
+	for _, p := range photos {
+		if p.GPS != nil {
+			m.markers = append(m.markers, mapMarker{p, p.GPS.Lat, p.GPS.Long})
+		}
+	}
+	m.photos++
+	m.Refresh()
+}
+
+// Web Mercator world pixel coordinates at zoom
+func project(lat, long float64, zoom int) (x, y float64) {
+	lat = math.Max(-maxMercatorLat, math.Min(maxMercatorLat, lat))
+	size := math.Ldexp(tileSize, zoom)
+	x = (long + 180) / 360 * size
+	r := lat * math.Pi / 180
+	y = (1 - math.Log(math.Tan(r)+1/math.Cos(r))/math.Pi) / 2 * size
+	return x, y
+}
+
+// latitude and longitude of world pixel coordinates at zoom
+func unproject(x, y float64, zoom int) (lat, long float64) {
+	size := math.Ldexp(tileSize, zoom)
+	long = x/size*360 - 180
+	lat = math.Atan(math.Sinh(math.Pi*(1-2*y/size))) * 180 / math.Pi
+	return lat, long
+}
+
+// screen position of world pixel coordinates
+func (m *MapView) screen(x, y float64) fyne.Position {
+	size := m.Size()
+	return fyne.NewPos(float32(x-m.centerX)+size.Width/2, float32(y-m.centerY)+size.Height/2)
+}
+
+// change zoom keeping the center
+func (m *MapView) SetZoom(zoom int) {
+	if zoom < 0 || zoom > maxMapZoom {
+		return
+	}
+	lat, long := unproject(m.centerX, m.centerY, m.zoom)
+	m.zoom = zoom
+	m.centerX, m.centerY = project(lat, long, zoom)
+	m.Refresh()
+}
+
+func (m *MapView) ZoomIn()  { m.SetZoom(m.zoom + 1) }
+func (m *MapView) ZoomOut() { m.SetZoom(m.zoom - 1) }
+
+// center on position
+func (m *MapView) CenterOn(lat, long float64, zoom int) {
+	m.zoom = zoom
+	m.centerX, m.centerY = project(lat, long, zoom)
+	m.Refresh()
+}
+
+// zoom and center to show all markers
+func (m *MapView) FitAll() {
+	if len(m.markers) == 0 {
+		m.CenterOn(0, 0, 1)
+		return
+	}
+	minLat, maxLat, minLong, maxLong := 90.0, -90.0, 180.0, -180.0
+	for _, mk := range m.markers {
+		minLat, maxLat = math.Min(minLat, mk.lat), math.Max(maxLat, mk.lat)
+		minLong, maxLong = math.Min(minLong, mk.long), math.Max(maxLong, mk.long)
+	}
+	size := m.Size()
+	zoom := maxMapZoom - 3
+	for ; zoom > 0; zoom-- {
+		x1, y1 := project(maxLat, minLong, zoom)
+		x2, y2 := project(minLat, maxLong, zoom)
+		if float32(x2-x1) < size.Width-4*clusterRadius && float32(y2-y1) < size.Height-4*clusterRadius {
+			break
+		}
+	}
+	x1, y1 := project(maxLat, minLong, zoom)
+	x2, y2 := project(minLat, maxLong, zoom)
+	m.zoom = zoom
+	m.centerX, m.centerY = (x1+x2)/2, (y1+y2)/2
+	m.fitted = true
+	m.Refresh()
+}
+
+// group markers by screen cells
+func (m *MapView) cluster() {
+	m.clusters = m.clusters[:0]
+	cells := map[[2]int]int{}
+	sums := []fyne.Position{}
+	for _, mk := range m.markers {
+		pos := m.screen(project(mk.lat, mk.long, m.zoom))
+		key := [2]int{int(math.Floor(float64(pos.X / clusterCell))), int(math.Floor(float64(pos.Y / clusterCell)))}
+		i, ok := cells[key]
+		if !ok {
+			i = len(m.clusters)
+			cells[key] = i
+			m.clusters = append(m.clusters, mapCluster{})
+			sums = append(sums, fyne.Position{})
+		}
+		m.clusters[i].photos = append(m.clusters[i].photos, mk.photo)
+		sums[i] = sums[i].Add(pos)
+	}
+	for i := range m.clusters {
+		n := float32(len(m.clusters[i].photos))
+		m.clusters[i].pos = fyne.NewPos(sums[i].X/n, sums[i].Y/n)
+	}
+}
+
+func (m *MapView) Dragged(e *fyne.DragEvent) {
+	m.centerX -= float64(e.Dragged.DX)
+	m.centerY -= float64(e.Dragged.DY)
+	m.Refresh()
+}
+
+func (m *MapView) DragEnd() {}
+
+func (m *MapView) Scrolled(e *fyne.ScrollEvent) {
+	switch {
+	case e.Scrolled.DY > 0:
+		m.ZoomIn()
+	case e.Scrolled.DY < 0:
+		m.ZoomOut()
+	}
+}
+
+// select photo of tapped marker, zoom into tapped cluster
+func (m *MapView) Tapped(e *fyne.PointEvent) {
+	for _, c := range m.clusters {
+		r := float32(markerRadius)
+		if len(c.photos) > 1 {
+			r = clusterRadius
+		}
+		d := c.pos.Subtract(e.Position)
+		if d.X*d.X+d.Y*d.Y > (r+4)*(r+4) {
+			continue
+		}
+		if len(c.photos) > 1 && m.zoom < maxMapZoom {
+			m.centerX += float64(c.pos.X - m.Size().Width/2)
+			m.centerY += float64(c.pos.Y - m.Size().Height/2)
+			m.SetZoom(m.zoom + 2)
+			return
+		}
+		if m.OnSelected != nil {
+			m.OnSelected(c.photos[0])
+		}
+		return
+	}
+}
+
+func (m *MapView) MinSize() fyne.Size {
+	return fyne.NewSize(200, 200)
+}
+
+func (m *MapView) CreateRenderer() fyne.WidgetRenderer {
+	return &mapRenderer{m: m, tiles: map[string]*canvas.Image{}, missing: map[string]bool{}}
+}
+
+type mapRenderer struct {
+	m           *MapView
+	objects     []fyne.CanvasObject
+	tiles       map[string]*canvas.Image // loaded tiles by path without extension
+	tileOrder   []string                 // loaded tiles, oldest first
+	missing     map[string]bool          // tiles not found by path without extension
+	mbtiles     *mbTiles                 // open MBTiles file
+	mbtilesFile string
+	outlines    [][][2]float64
+	loaded      string   // path of loaded outlines
+	built       mapState // view the objects were built for
+}
+
+// map view state, objects are rebuilt when it changes
+type mapState struct {
+	zoom             int
+	centerX, centerY float64
+	size             fyne.Size
+	photos           int
+	tiles, outlines  string
+	fg               color.Color
+}
+
+func (r *mapRenderer) state() mapState {
+	prefs := fyne.CurrentApp().Preferences()
+	return mapState{
+		zoom:     r.m.zoom,
+		centerX:  r.m.centerX,
+		centerY:  r.m.centerY,
+		size:     r.m.Size(),
+		photos:   r.m.photos,
+		tiles:    prefs.String(prefMapTiles),
+		outlines: prefs.String(prefMapOutlines),
+		fg:       theme.ForegroundColor(),
+	}
+}
+
+// keep objects centered, they are rebuilt only if the map grows over the area they were built for
+func (r *mapRenderer) Layout(size fyne.Size) {
+	old := r.built.size
+	if size == old {
+		return
+	}
+	if size.Width > old.Width || size.Height > old.Height {
+		r.Refresh()
+		return
+	}
+	d := fyne.NewPos((size.Width-old.Width)/2, (size.Height-old.Height)/2)
+	r.objects[0].Resize(size)
+	for _, o := range r.objects[1 : len(r.objects)-1] {
+		o.Move(o.Position().Add(d))
+	}
+	zoom := r.objects[len(r.objects)-1]
+	zoom.Move(fyne.NewPos(4, size.Height-zoom.MinSize().Height-2))
+	for i := range r.m.clusters {
+		r.m.clusters[i].pos = r.m.clusters[i].pos.Add(d)
+	}
+	r.built.size = size
+}
+
+func (r *mapRenderer) MinSize() fyne.Size { return r.m.MinSize() }
+
+func (r *mapRenderer) Objects() []fyne.CanvasObject { return r.objects }
+
+func (r *mapRenderer) Destroy() {
+	r.closeMBTiles()
+}
+
+// rebuild map objects if view changed, background is the first object and zoom label the last one
+func (r *mapRenderer) Refresh() {
+	s := r.state()
+	if s == r.built && r.objects != nil {
+		return
+	}
+	r.built = s
+	size := s.size
+	bg := canvas.NewRectangle(theme.InputBackgroundColor())
+	bg.Resize(size)
+	r.objects = []fyne.CanvasObject{bg}
+	if !r.addTiles(s.tiles, size) {
+		r.addGrid(size)
+		r.addOutlines(s.outlines, size)
+	}
+	r.addMarkers()
+	attribution := canvas.NewText(fmt.Sprintf("zoom %d", r.m.zoom), theme.DisabledColor())
+	attribution.TextSize = theme.CaptionTextSize()
+	attribution.Move(fyne.NewPos(4, size.Height-attribution.MinSize().Height-2))
+	r.objects = append(r.objects, attribution)
+	canvas.Refresh(r.m)
+}
+
+// add visible tiles from tile directory or MBTiles file, false if there is none or none of the tiles is found
+func (r *mapRenderer) addTiles(dir string, size fyne.Size) bool {
+	if dir == "" {
+		return false
+	}
+	m := r.m
+	n := 1 << m.zoom
+	x0 := int(math.Floor((m.centerX - float64(size.Width)/2) / tileSize))
+	x1 := int(math.Floor((m.centerX + float64(size.Width)/2) / tileSize))
+	y0 := int(math.Max(0, math.Floor((m.centerY-float64(size.Height)/2)/tileSize)))
+	y1 := int(math.Min(float64(n-1), math.Floor((m.centerY+float64(size.Height)/2)/tileSize)))
+	found := false
+	for ty := y0; ty <= y1; ty++ {
+		for tx := x0; tx <= x1; tx++ {
+			img := r.tile(dir, m.zoom, ((tx%n)+n)%n, ty)
+			if img == nil {
+				continue
+			}
+			found = true
+			img.Resize(fyne.NewSize(tileSize, tileSize))
+			img.Move(m.screen(float64(tx*tileSize), float64(ty*tileSize)))
+			r.objects = append(r.objects, img)
+		}
+	}
+	return found
+}
+
+// tile image from dir/z/x/y.png or .jpg or from MBTiles file, nil if there is none
+func (r *mapRenderer) tile(source string, z, x, y int) *canvas.Image {
+	base := filepath.Join(source, strconv.Itoa(z), strconv.Itoa(x), strconv.Itoa(y))
+	if img, ok := r.tiles[base]; ok {
+		return img
+	}
+	if r.missing[base] {
+		return nil
+	}
+	var img *canvas.Image
+	if strings.EqualFold(filepath.Ext(source), ".mbtiles") {
+		img = r.mbTile(source, z, x, y)
+	} else {
+		for _, ext := range []string{".png", ".jpg", ".jpeg"} {
+			if _, err := os.Stat(base + ext); err == nil {
+				img = canvas.NewImageFromFile(base + ext)
+				break
+			}
+		}
+	}
+	if img == nil {
+		if len(r.missing) >= maxMissingTiles {
+			r.missing = map[string]bool{}
+		}
+		r.missing[base] = true
+		return nil
+	}
+	img.FillMode = canvas.ImageFillStretch
+	r.tiles[base] = img
+	r.tileOrder = append(r.tileOrder, base)
+	if len(r.tileOrder) > maxCachedTiles {
+		delete(r.tiles, r.tileOrder[0])
+		r.tileOrder = r.tileOrder[1:]
+	}
+	return img
+}
+
+// tile image from MBTiles file opened on first use, nil if there is none
+func (r *mapRenderer) mbTile(file string, z, x, y int) *canvas.Image {
+	if file != r.mbtilesFile {
+		r.closeMBTiles()
+		r.mbtilesFile = file
+		mb, err := openMBTiles(file)
+		if err != nil {
+			log.Printf("Map tiles %s: %v", file, err)
+			return nil
+		}
+		r.mbtiles = mb
+	}
+	if r.mbtiles == nil {
+		return nil
+	}
+	data, err := r.mbtiles.tile(z, x, y)
+	if err != nil {
+		log.Printf("Map tiles %s: %v", file, err)
+		return nil
+	}
+	if data == nil {
+		return nil
+	}
+	m, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil
+	}
+	return canvas.NewImageFromImage(m)
+}
+
+func (r *mapRenderer) closeMBTiles() {
+	if r.mbtiles != nil {
+		r.mbtiles.Close()
+		r.mbtiles = nil
+	}
+	r.mbtilesFile = ""
+}
+
+// add latitude and longitude lines with labels
+func (r *mapRenderer) addGrid(size fyne.Size) {
+	m := r.m
+	// grid step of about 100 pixels
+	degPerPx := 360 / math.Ldexp(tileSize, m.zoom)
+	step := 30.0
+	for _, s := range []float64{30, 10, 5, 2, 1, 0.5, 0.2, 0.1, 0.05, 0.02, 0.01, 0.005, 0.002, 0.001} {
+		if s/degPerPx < 100 {
+			break
+		}
+		step = s
+	}
+	lineColor := theme.ShadowColor()
+	textColor := theme.DisabledColor()
+	top, left := unproject(m.centerX-float64(size.Width)/2, m.centerY-float64(size.Height)/2, m.zoom)
+	bottom, right := unproject(m.centerX+float64(size.Width)/2, m.centerY+float64(size.Height)/2, m.zoom)
+	for long := math.Ceil(math.Max(left, -180)/step) * step; long <= math.Min(right, 180); long += step {
+		x, _ := project(0, long, m.zoom)
+		p := m.screen(x, 0)
+		line := canvas.NewLine(lineColor)
+		line.Position1, line.Position2 = fyne.NewPos(p.X, 0), fyne.NewPos(p.X, size.Height)
+		label := canvas.NewText(strconv.FormatFloat(long, 'f', -1, 64)+"°", textColor)
+		label.TextSize = theme.CaptionTextSize()
+		label.Move(fyne.NewPos(p.X+2, 2))
+		r.objects = append(r.objects, line, label)
+	}
+	for lat := math.Ceil(math.Max(bottom, -maxMercatorLat)/step) * step; lat <= math.Min(top, maxMercatorLat); lat += step {
+		_, y := project(lat, 0, m.zoom)
+		p := m.screen(0, y)
+		line := canvas.NewLine(lineColor)
+		line.Position1, line.Position2 = fyne.NewPos(0, p.Y), fyne.NewPos(size.Width, p.Y)
+		label := canvas.NewText(strconv.FormatFloat(lat, 'f', -1, 64)+"°", textColor)
+		label.TextSize = theme.CaptionTextSize()
+		label.Move(fyne.NewPos(2, p.Y+2))
+		r.objects = append(r.objects, line, label)
+	}
+}
+
+// add outline polylines from GeoJSON file, built-in world outlines if file is empty
+func (r *mapRenderer) addOutlines(file string, size fyne.Size) {
+	if file != r.loaded || r.outlines == nil {
+		r.loaded = file
+		var err error
+		if file == "" {
+			r.outlines, err = parseOutlines(worldOutlines)
+		} else {
+			r.outlines, err = loadOutlines(file)
+		}
+		if err != nil {
+			fyne.LogError("Map outlines load error:", err)
+			r.outlines = [][][2]float64{}
+		}
+	}
+	m := r.m
+	c := theme.ForegroundColor()
+	for _, line := range r.outlines {
+		var prev fyne.Position
+		for i, pt := range line {
+			pos := m.screen(project(pt[1], pt[0], m.zoom))
+			if i > 0 && (inside(prev, size) || inside(pos, size)) {
+				seg := canvas.NewLine(c)
+				seg.Position1, seg.Position2 = prev, pos
+				r.objects = append(r.objects, seg)
+			}
+			prev = pos
+		}
+	}
+}
+
+func inside(p fyne.Position, size fyne.Size) bool {
+	return p.X >= 0 && p.Y >= 0 && p.X <= size.Width && p.Y <= size.Height
+}
+
+// add photo markers, clusters show photo count
+func (r *mapRenderer) addMarkers() {
+	m := r.m
+	m.cluster()
+	for _, c := range m.clusters {
+		radius := float32(markerRadius)
+		if len(c.photos) > 1 {
+			radius = clusterRadius
+		}
+		dot := canvas.NewCircle(theme.PrimaryColor())
+		dot.StrokeColor = color.White
+		dot.StrokeWidth = 2
+		dot.Resize(fyne.NewSize(2*radius, 2*radius))
+		dot.Move(c.pos.SubtractXY(radius, radius))
+		r.objects = append(r.objects, dot)
+		if len(c.photos) > 1 {
+			count := canvas.NewText(strconv.Itoa(len(c.photos)), color.White)
+			count.TextSize = theme.CaptionTextSize()
+			count.TextStyle.Bold = true
+			s := count.MinSize()
+			count.Move(c.pos.SubtractXY(s.Width/2, s.Height/2))
+			r.objects = append(r.objects, count)
+		}
+	}
+}
+
+// GeoJSON geometry
+type geoJSONGeometry struct {
+	Type        string            `json:"type"`
+	Coordinates json.RawMessage   `json:"coordinates"`
+	Geometries  []geoJSONGeometry `json:"geometries"`
+}
+
+// load polygon and line outlines of GeoJSON file as polylines of [long, lat] points
+func loadOutlines(file string) ([][][2]float64, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	lines, err := parseOutlines(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filepath.Base(file), err)
+	}
+	return lines, nil
+}
+
+// polygon and line outlines of GeoJSON data
+func parseOutlines(data []byte) ([][][2]float64, error) {
+	var doc struct {
+		geoJSONGeometry
+		Geometry *geoJSONGeometry `json:"geometry"`
+		Features []struct {
+			Geometry *geoJSONGeometry `json:"geometry"`
+		} `json:"features"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	geometries := []geoJSONGeometry{doc.geoJSONGeometry}
+	if doc.Geometry != nil {
+		geometries = append(geometries, *doc.Geometry)
+	}
+	for _, f := range doc.Features {
+		if f.Geometry != nil {
+			geometries = append(geometries, *f.Geometry)
+		}
+	}
+	lines := [][][2]float64{}
+	var add func(g geoJSONGeometry)
+	add = func(g geoJSONGeometry) {
+		switch g.Type {
+		case "LineString":
+			var line [][2]float64
+			if json.Unmarshal(g.Coordinates, &line) == nil {
+				lines = append(lines, line)
+			}
+		case "MultiLineString", "Polygon":
+			var ls [][][2]float64
+			if json.Unmarshal(g.Coordinates, &ls) == nil {
+				lines = append(lines, ls...)
+			}
+		case "MultiPolygon":
+			var ps [][][][2]float64
+			if json.Unmarshal(g.Coordinates, &ps) == nil {
+				for _, p := range ps {
+					lines = append(lines, p...)
+				}
+			}
+		case "GeometryCollection":
+			for _, gg := range g.Geometries {
+				add(gg)
+			}
+		}
+	}
+	for _, g := range geometries {
+		add(g)
+	}
+	return lines, nil
+}
+
+// create map tab content
+func (l *PhotoList) newMapTab() *container.TabItem {
+	l.mapView = newMapView(l.showPhoto)
+	l.mapView.SetPhotos(l.List)
+	toolBar := widget.NewToolbar(
+		widget.NewToolbarAction(theme.ZoomInIcon(), l.mapView.ZoomIn),
+		widget.NewToolbarAction(theme.ZoomOutIcon(), l.mapView.ZoomOut),
+		widget.NewToolbarAction(theme.ZoomFitIcon(), l.mapView.FitAll),
+		widget.NewToolbarSpacer(),
+		widget.NewToolbarAction(theme.SettingsIcon(), settingsScreen),
+		widget.NewToolbarAction(theme.HelpIcon(), aboutScreen),
+	)
+	return container.NewTabItemWithIcon("Map", theme.MediaRecordIcon(), container.NewBorder(toolBar, nil, nil, nil, l.mapView))
+}
+
+// update markers when map tab is shown, fit view to them the first time
+func (l *PhotoList) showMap() {
+	l.mapView.SetPhotos(l.List)
+	if !l.mapView.fitted {
+		l.mapView.FitAll()
+	}
+}
+
+// scroll Choice tab frame to photo and show it
+func (l *PhotoList) showPhoto(p *Photo) {
+	for i, ph := range l.View {
+		if ph == p {
+			l.scrollFrame(i)
+			break
+		}
+	}
+	if l.tabs != nil {
+		l.tabs.SelectIndex(0)
+	}
+}
+
+func (s *Settings) mapTilesRow() *widget.Entry {
+	e := widget.NewEntry()
+	e.SetPlaceHolder("Folder with z/x/y.png tiles or .mbtiles file, grid is drawn if empty")
+	e.SetText(fyne.CurrentApp().Preferences().String(prefMapTiles))
+	e.OnChanged = func(text string) {
+		fyne.CurrentApp().Preferences().SetString(prefMapTiles, strings.TrimSpace(text))
+	}
+	return e
+}
+
+func (s *Settings) mapOutlinesRow() *widget.Entry {
+	e := widget.NewEntry()
+	e.SetPlaceHolder("GeoJSON file with outlines drawn over the grid, built-in world outlines if empty")
+	e.SetText(fyne.CurrentApp().Preferences().String(prefMapOutlines))
+	e.OnChanged = func(text string) {
+		fyne.CurrentApp().Preferences().SetString(prefMapOutlines, strings.TrimSpace(text))
+	}
+	return e
+}
@@ -0,0 +1,664 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+)
+
+// Read-only access to tiles of MBTiles file. MBTiles is an SQLite database, only table and index
+// b-trees needed to find tiles are read: tiles table or common tiles view over map and images tables.
+
+var (
+	errNotSQLite     = errors.New("not an SQLite 3 database")
+	errSQLiteCorrupt = errors.New("corrupt SQLite database")
+	errNoTilesTable  = errors.New("no tiles table in MBTiles file")
+)
+
+const maxBtreeDepth = 20 // guard against page loops in corrupt files
+
+// SQLite database file
+type sqliteFile struct {
+	f        *os.File
+	pageSize int
+	usable   int // page size without reserved bytes
+}
+
+func openSQLite(name string) (*sqliteFile, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	hdr := make([]byte, 100)
+	if _, err := io.ReadFull(f, hdr); err != nil || string(hdr[:16]) != "SQLite format 3\x00" {
+		f.Close()
+		return nil, errNotSQLite
+	}
+	size := int(binary.BigEndian.Uint16(hdr[16:]))
+	if size == 1 {
+		size = 65536
+	}
+	if size < 512 || size&(size-1) != 0 || binary.BigEndian.Uint32(hdr[56:]) > 1 { // UTF-8 text only
+		f.Close()
+		return nil, errSQLiteCorrupt
+	}
+	return &sqliteFile{f: f, pageSize: size, usable: size - int(hdr[20])}, nil
+}
+
+func (s *sqliteFile) Close() error {
+	return s.f.Close()
+}
+
+func (s *sqliteFile) page(n uint32) ([]byte, error) {
+	if n == 0 {
+		return nil, errSQLiteCorrupt
+	}
+	p := make([]byte, s.pageSize)
+	if _, err := s.f.ReadAt(p, int64(n-1)*int64(s.pageSize)); err != nil {
+		return nil, errSQLiteCorrupt
+	}
+	return p, nil
+}
+
+// b-tree page types
+const (
+	btreeIndexInterior = 0x02
+	btreeTableInterior = 0x05
+	btreeIndexLeaf     = 0x0a
+	btreeTableLeaf     = 0x0d
+)
+
+type btreePage struct {
+	data  []byte
+	kind  byte
+	cells []int  // cell offsets
+	right uint32 // right-most child of interior page
+}
+
+func (s *sqliteFile) btree(n uint32) (*btreePage, error) {
+	data, err := s.page(n)
+	if err != nil {
+		return nil, err
+	}
+	h := 0
+	if n == 1 {
+		h = 100 // database header
+	}
+	p := &btreePage{data: data, kind: data[h]}
+	ptrs := h + 8
+	switch p.kind {
+	case btreeIndexInterior, btreeTableInterior:
+		p.right = binary.BigEndian.Uint32(data[h+8:])
+		ptrs = h + 12
+	case btreeIndexLeaf, btreeTableLeaf:
+	default:
+		return nil, errSQLiteCorrupt
+	}
+	count := int(binary.BigEndian.Uint16(data[h+3:]))
+	if ptrs+2*count > len(data) {
+		return nil, errSQLiteCorrupt
+	}
+	for i := 0; i < count; i++ {
+		ofs := int(binary.BigEndian.Uint16(data[ptrs+2*i:]))
+		if ofs < ptrs || ofs >= s.usable {
+			return nil, errSQLiteCorrupt
+		}
+		p.cells = append(p.cells, ofs)
+	}
+	return p, nil
+}
+
+// SQLite variable length integer and its length, length is 0 if it is truncated
+func sqliteVarint(b []byte) (uint64, int) {
+	var v uint64
+	for i := 0; i < 9 && i < len(b); i++ {
+		if i == 8 {
+			return v<<8 | uint64(b[i]), 9
+		}
+		v = v<<7 | uint64(b[i]&0x7f)
+		if b[i] < 0x80 {
+			return v, i + 1
+		}
+	}
+	return 0, 0
+}
+
+// cell payload of size at ofs, overflow pages are followed
+func (s *sqliteFile) payload(p *btreePage, ofs int, size uint64, table bool) ([]byte, error) {
+	u := s.usable
+	if size > math.MaxInt32 {
+		return nil, errSQLiteCorrupt
+	}
+	n := int(size)
+	maxLocal := u - 35
+	if !table {
+		maxLocal = (u-12)*64/255 - 23
+	}
+	local := n
+	if n > maxLocal {
+		minLocal := (u-12)*32/255 - 23
+		local = minLocal + (n-minLocal)%(u-4)
+		if local > maxLocal {
+			local = minLocal
+		}
+	}
+	if ofs+local > len(p.data) {
+		return nil, errSQLiteCorrupt
+	}
+	out := append(make([]byte, 0, n), p.data[ofs:ofs+local]...)
+	if local == n {
+		return out, nil
+	}
+	if ofs+local+4 > len(p.data) {
+		return nil, errSQLiteCorrupt
+	}
+	next := binary.BigEndian.Uint32(p.data[ofs+local:])
+	for len(out) < n {
+		page, err := s.page(next)
+		if err != nil {
+			return nil, err
+		}
+		next = binary.BigEndian.Uint32(page)
+		k := u - 4
+		if k > n-len(out) {
+			k = n - len(out)
+		}
+		out = append(out, page[4:4+k]...)
+	}
+	return out, nil
+}
+
+// record values: nil, int64, float64, string or []byte
+func sqliteRecord(b []byte) ([]any, error) {
+	hsize, n := sqliteVarint(b)
+	if n == 0 || hsize > uint64(len(b)) || int(hsize) < n {
+		return nil, errSQLiteCorrupt
+	}
+	types := []uint64{}
+	for i := n; i < int(hsize); {
+		t, k := sqliteVarint(b[i:hsize])
+		if k == 0 {
+			return nil, errSQLiteCorrupt
+		}
+		types = append(types, t)
+		i += k
+	}
+	values := make([]any, len(types))
+	pos := int(hsize)
+	for i, t := range types {
+		size := 0
+		switch {
+		case t >= 1 && t <= 6:
+			size = []int{0, 1, 2, 3, 4, 6, 8}[t]
+		case t == 7:
+			size = 8
+		case t >= 12:
+			size = int((t - 12) / 2)
+		case t == 10 || t == 11:
+			return nil, errSQLiteCorrupt
+		}
+		if pos+size > len(b) || size < 0 {
+			return nil, errSQLiteCorrupt
+		}
+		v := b[pos : pos+size]
+		pos += size
+		switch {
+		case t == 0:
+			values[i] = nil
+		case t <= 6:
+			var x int64
+			for _, c := range v {
+				x = x<<8 | int64(c)
+			}
+			shift := 64 - 8*size
+			values[i] = x << shift >> shift
+		case t == 7:
+			values[i] = math.Float64frombits(binary.BigEndian.Uint64(v))
+		case t == 8:
+			values[i] = int64(0)
+		case t == 9:
+			values[i] = int64(1)
+		case t%2 == 0:
+			values[i] = append([]byte{}, v...)
+		default:
+			values[i] = string(v)
+		}
+	}
+	return values, nil
+}
+
+// SQLite sort order of values: NULL, numbers, text, blob
+func sqliteCompare(a, b any) int {
+	rank := func(v any) int {
+		switch v.(type) {
+		case nil:
+			return 0
+		case int64, float64:
+			return 1
+		case string:
+			return 2
+		}
+		return 3
+	}
+	if ra, rb := rank(a), rank(b); ra != rb {
+		return ra - rb
+	}
+	switch a := a.(type) {
+	case int64:
+		if b, ok := b.(int64); ok {
+			return compareOrdered(a, b)
+		}
+		return compareOrdered(float64(a), b.(float64))
+	case float64:
+		if b, ok := b.(int64); ok {
+			return compareOrdered(a, float64(b))
+		}
+		return compareOrdered(a, b.(float64))
+	case string:
+		return strings.Compare(a, b.(string))
+	case []byte:
+		return bytes.Compare(a, b.([]byte))
+	}
+	return 0
+}
+
+func compareOrdered[T int64 | float64](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// call fn for each row of table b-tree
+func (s *sqliteFile) scan(root uint32, fn func(rowid int64, values []any) error) error {
+	var walk func(n uint32, depth int) error
+	walk = func(n uint32, depth int) error {
+		if depth > maxBtreeDepth {
+			return errSQLiteCorrupt
+		}
+		p, err := s.btree(n)
+		if err != nil {
+			return err
+		}
+		for _, ofs := range p.cells {
+			switch p.kind {
+			case btreeTableInterior:
+				if err := walk(binary.BigEndian.Uint32(p.data[ofs:]), depth+1); err != nil {
+					return err
+				}
+			case btreeTableLeaf:
+				rowid, values, err := s.tableCell(p, ofs)
+				if err != nil {
+					return err
+				}
+				if err := fn(rowid, values); err != nil {
+					return err
+				}
+			default:
+				return errSQLiteCorrupt
+			}
+		}
+		if p.kind == btreeTableInterior {
+			return walk(p.right, depth+1)
+		}
+		return nil
+	}
+	return walk(root, 0)
+}
+
+// rowid and values of table leaf cell
+func (s *sqliteFile) tableCell(p *btreePage, ofs int) (int64, []any, error) {
+	size, n := sqliteVarint(p.data[ofs:])
+	rowid, k := sqliteVarint(p.data[ofs+n:])
+	if n == 0 || k == 0 {
+		return 0, nil, errSQLiteCorrupt
+	}
+	b, err := s.payload(p, ofs+n+k, size, true)
+	if err != nil {
+		return 0, nil, err
+	}
+	values, err := sqliteRecord(b)
+	return int64(rowid), values, err
+}
+
+// values of table row, nil if there is no such row
+func (s *sqliteFile) row(root uint32, rowid int64) ([]any, error) {
+	n := root
+	for depth := 0; depth <= maxBtreeDepth; depth++ {
+		p, err := s.btree(n)
+		if err != nil {
+			return nil, err
+		}
+		switch p.kind {
+		case btreeTableInterior:
+			n = p.right
+			for _, ofs := range p.cells {
+				key, k := sqliteVarint(p.data[ofs+4:])
+				if k == 0 {
+					return nil, errSQLiteCorrupt
+				}
+				if rowid <= int64(key) {
+					n = binary.BigEndian.Uint32(p.data[ofs:])
+					break
+				}
+			}
+		case btreeTableLeaf:
+			for _, ofs := range p.cells {
+				id, values, err := s.tableCell(p, ofs)
+				if err != nil {
+					return nil, err
+				}
+				if id == rowid {
+					return values, nil
+				}
+			}
+			return nil, nil
+		default:
+			return nil, errSQLiteCorrupt
+		}
+	}
+	return nil, errSQLiteCorrupt
+}
+
+// rowid of index entry with leading columns equal to key, false if there is none
+func (s *sqliteFile) indexFind(root uint32, key []any) (int64, bool, error) {
+	match := func(values []any) (int, int64, bool) {
+		if len(values) <= len(key) {
+			return 0, 0, false
+		}
+		for i, k := range key {
+			if c := sqliteCompare(values[i], k); c != 0 {
+				return c, 0, true
+			}
+		}
+		rowid, ok := values[len(values)-1].(int64)
+		return 0, rowid, ok
+	}
+	n := root
+	for depth := 0; depth <= maxBtreeDepth; depth++ {
+		p, err := s.btree(n)
+		if err != nil {
+			return 0, false, err
+		}
+		interior := p.kind == btreeIndexInterior
+		if !interior && p.kind != btreeIndexLeaf {
+			return 0, false, errSQLiteCorrupt
+		}
+		next := p.right
+		for _, ofs := range p.cells {
+			cell := ofs
+			if interior {
+				cell += 4 // left child
+			}
+			size, k := sqliteVarint(p.data[cell:])
+			if k == 0 {
+				return 0, false, errSQLiteCorrupt
+			}
+			b, err := s.payload(p, cell+k, size, false)
+			if err != nil {
+				return 0, false, err
+			}
+			values, err := sqliteRecord(b)
+			if err != nil {
+				return 0, false, err
+			}
+			c, rowid, ok := match(values)
+			if !ok {
+				return 0, false, errSQLiteCorrupt
+			}
+			if c == 0 {
+				return rowid, true, nil
+			}
+			if c > 0 {
+				if !interior {
+					return 0, false, nil
+				}
+				next = binary.BigEndian.Uint32(p.data[ofs:])
+				break
+			}
+		}
+		if !interior {
+			return 0, false, nil
+		}
+		n = next
+	}
+	return 0, false, errSQLiteCorrupt
+}
+
+// table of schema with column names
+type sqliteTable struct {
+	root  uint32
+	cols  []string
+	alias int // column of INTEGER PRIMARY KEY stored as rowid, -1 if there is none
+}
+
+func (t sqliteTable) value(rowid int64, values []any, col string) any {
+	for i, c := range t.cols {
+		if !strings.EqualFold(c, col) {
+			continue
+		}
+		if i == t.alias {
+			return rowid
+		}
+		if i < len(values) {
+			return values[i]
+		}
+	}
+	return nil
+}
+
+// columns of CREATE TABLE or CREATE INDEX statement and INTEGER PRIMARY KEY column, -1 if there is none
+func sqlColumns(sql string) ([]string, int) {
+	i, j := strings.Index(sql, "("), strings.LastIndex(sql, ")")
+	if i < 0 || j < i {
+		return nil, -1
+	}
+	parts := []string{}
+	depth, start := 0, i+1
+	for k := i + 1; k < j; k++ {
+		switch sql[k] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, sql[start:k])
+				start = k + 1
+			}
+		}
+	}
+	parts = append(parts, sql[start:j])
+	cols, alias := []string{}, -1
+	for _, part := range parts {
+		fields := strings.Fields(part)
+		if len(fields) == 0 {
+			continue
+		}
+		switch strings.ToUpper(fields[0]) {
+		case "PRIMARY", "UNIQUE", "CHECK", "FOREIGN", "CONSTRAINT":
+			continue
+		}
+		upper := strings.ToUpper(strings.Join(fields, " "))
+		if len(fields) > 1 && strings.ToUpper(fields[1]) == "INTEGER" && strings.Contains(upper, "PRIMARY KEY") {
+			alias = len(cols)
+		}
+		cols = append(cols, strings.Trim(fields[0], "\"`[]'"))
+	}
+	return cols, alias
+}
+
+// tables and indexes of schema
+type sqliteSchema struct {
+	tables  map[string]sqliteTable
+	views   map[string]bool
+	indexes map[string][]sqliteTable // indexes by table name, cols are key columns
+}
+
+func (s *sqliteFile) schema() (*sqliteSchema, error) {
+	schema := &sqliteSchema{tables: map[string]sqliteTable{}, views: map[string]bool{}, indexes: map[string][]sqliteTable{}}
+	err := s.scan(1, func(_ int64, v []any) error {
+		if len(v) < 5 {
+			return errSQLiteCorrupt
+		}
+		kind, _ := v[0].(string)
+		name, _ := v[1].(string)
+		table, _ := v[2].(string)
+		root, _ := v[3].(int64)
+		sql, _ := v[4].(string)
+		cols, alias := sqlColumns(sql)
+		name, table = strings.ToLower(name), strings.ToLower(table)
+		switch kind {
+		case "table":
+			schema.tables[name] = sqliteTable{root: uint32(root), cols: cols, alias: alias}
+		case "view":
+			schema.views[name] = true
+		case "index":
+			if sql != "" { // automatic indexes have no statement
+				schema.indexes[table] = append(schema.indexes[table], sqliteTable{root: uint32(root), cols: cols, alias: -1})
+			}
+		}
+		return nil
+	})
+	return schema, err
+}
+
+// root of index of table starting with columns, 0 if there is none
+func (s *sqliteSchema) index(table string, cols ...string) uint32 {
+next:
+	for _, ix := range s.indexes[table] {
+		if len(ix.cols) < len(cols) {
+			continue
+		}
+		for i, c := range cols {
+			if !strings.EqualFold(ix.cols[i], c) {
+				continue next
+			}
+		}
+		return ix.root
+	}
+	return 0
+}
+
+// tile lookup in table, by index or by map built on the first lookup if there is no index
+type tileLookup struct {
+	table sqliteTable
+	index uint32
+	cols  []string
+	rows  map[string]int64 // rowids by key if there is no index
+}
+
+func (l *tileLookup) rowid(db *sqliteFile, key ...any) (int64, bool, error) {
+	if l.index != 0 {
+		return db.indexFind(l.index, key)
+	}
+	if l.rows == nil {
+		l.rows = map[string]int64{}
+		err := db.scan(l.table.root, func(rowid int64, values []any) error {
+			k := []any{}
+			for _, c := range l.cols {
+				k = append(k, l.table.value(rowid, values, c))
+			}
+			l.rows[lookupKey(k)] = rowid
+			return nil
+		})
+		if err != nil {
+			l.rows = nil
+			return 0, false, err
+		}
+	}
+	rowid, ok := l.rows[lookupKey(key)]
+	return rowid, ok, nil
+}
+
+func lookupKey(values []any) string {
+	var b strings.Builder
+	for _, v := range values {
+		if f, ok := v.(float64); ok && f == math.Trunc(f) {
+			v = int64(f) // integral real equals integer
+		}
+		fmt.Fprintf(&b, "%T%q;", v, fmt.Sprint(v))
+	}
+	return b.String()
+}
+
+// MBTiles file tile source
+type mbTiles struct {
+	db     *sqliteFile
+	tiles  *tileLookup // tiles table or map table of tiles view
+	images *tileLookup // images table of tiles view, nil for tiles table
+}
+
+var tileKeyColumns = []string{"zoom_level", "tile_column", "tile_row"}
+
+func openMBTiles(name string) (*mbTiles, error) {
+	db, err := openSQLite(name)
+	if err != nil {
+		return nil, err
+	}
+	schema, err := db.schema()
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	t := &mbTiles{db: db}
+	lookup := func(table string, cols ...string) *tileLookup {
+		return &tileLookup{table: schema.tables[table], index: schema.index(table, cols...), cols: cols}
+	}
+	_, hasMap := schema.tables["map"]
+	_, hasImages := schema.tables["images"]
+	switch _, hasTiles := schema.tables["tiles"]; {
+	case hasTiles:
+		t.tiles = lookup("tiles", tileKeyColumns...)
+	case schema.views["tiles"] && hasMap && hasImages:
+		t.tiles = lookup("map", tileKeyColumns...)
+		t.images = lookup("images", "tile_id")
+	default:
+		db.Close()
+		return nil, errNoTilesTable
+	}
+	return t, nil
+}
+
+func (t *mbTiles) Close() error {
+	return t.db.Close()
+}
+
+// image data of tile in XYZ scheme, nil if there is none
+func (t *mbTiles) tile(z, x, y int) ([]byte, error) {
+	row := int64(1)<<z - 1 - int64(y) // MBTiles rows are in TMS scheme
+	rowid, ok, err := t.tiles.rowid(t.db, int64(z), int64(x), row)
+	if !ok || err != nil {
+		return nil, err
+	}
+	values, err := t.db.row(t.tiles.table.root, rowid)
+	if values == nil || err != nil {
+		return nil, err
+	}
+	table := t.tiles.table
+	if t.images != nil {
+		id := table.value(rowid, values, "tile_id")
+		if rowid, ok, err = t.images.rowid(t.db, id); !ok || err != nil {
+			return nil, err
+		}
+		if values, err = t.db.row(t.images.table.root, rowid); values == nil || err != nil {
+			return nil, err
+		}
+		table = t.images.table
+	}
+	switch data := table.value(rowid, values, "tile_data").(type) {
+	case []byte:
+		return data, nil
+	case string:
+		return []byte(data), nil
+	}
+	return nil, nil
+}
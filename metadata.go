@@ -0,0 +1,279 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/tajtiattila/metadata/exif"
+	"github.com/tajtiattila/metadata/exif/exiftag"
+)
+
+// Metadata field groups
+const (
+	GroupSummary = "Summary"
+	GroupFile    = "File"
+	GroupEXIF    = "EXIF"
+	GroupGPS     = "GPS"
+	GroupXMP     = "XMP"
+	GroupIPTC    = "IPTC"
+)
+
+// EXIF tags missing in exiftag
+const (
+	LensMake  = exiftag.Exif | 0xa433
+	LensModel = exiftag.Exif | 0xa434
+)
+
+const maxFieldValueLen = 256
+
+// Metadata field decoded from photo file
+type MetaField struct {
+	Group string
+	Name  string
+	Value string
+}
+
+// decode all metadata fields of photo file
+func readMetaFields(file string) []MetaField {
+	fields := []MetaField{}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return append(fields, MetaField{GroupFile, "Error", err.Error()})
+	}
+	fields = append(fields,
+		MetaField{GroupFile, "Name", filepath.Base(file)},
+		MetaField{GroupFile, "Size", formatFileSize(int64(len(data)))},
+	)
+	if cfg, format, err := image.DecodeConfig(bytes.NewReader(data)); err == nil {
+		fields = append(fields,
+			MetaField{GroupFile, "Format", format},
+			MetaField{GroupFile, "Dimensions", fmt.Sprintf("%d x %d", cfg.Width, cfg.Height)},
+		)
+	}
+
+	x, err := exif.Decode(bytes.NewReader(data))
+	if err == nil || exif.IsFormat(err) && x != nil {
+		fields = append(fields, exifSummary(x)...)
+		fields = append(fields, exifFields(x)...)
+	}
+
+	segs, _, err := splitJpeg(data)
+	if err == nil {
+		if i := findSegment(segs, markerAPP1, xmpPrefix); i >= 0 {
+			fields = append(fields, xmpFields(segs[i][4+len(xmpPrefix):])...)
+		}
+		if i := findSegment(segs, markerAPP13, photoshopPrefix); i >= 0 {
+			for _, r := range parseIPTC(segs[i][4+len(photoshopPrefix):]) {
+				fields = append(fields, MetaField{GroupIPTC, r.name(), r.String()})
+			}
+		}
+	}
+	return fields
+}
+
+// main shooting parameters
+func exifSummary(x *exif.Exif) []MetaField {
+	fields := []MetaField{}
+	add := func(name, value string) {
+		if value != "" {
+			fields = append(fields, MetaField{GroupSummary, name, value})
+		}
+	}
+	add("Camera", getCamera(x))
+	add("Serial number", getCameraSerial(x))
+	add("Lens", strings.TrimSpace(exifString(x, LensMake)+" "+exifString(x, LensModel)))
+	add("Exposure", formatExposureTime(x))
+	add("Aperture", formatAperture(x))
+	add("ISO", formatISO(x))
+	add("Focal length", formatFocalLength(x))
+	add("Exposure compensation", formatExposureBias(x))
+	if lat, long, ok := x.LatLong(); ok {
+		add("GPS", formatLatLong(lat, long))
+	}
+	return fields
+}
+
+// all EXIF entries
+func exifFields(x *exif.Exif) []MetaField {
+	fields := []MetaField{}
+	for _, d := range []struct {
+		group string
+		dir   uint32
+		ifd   []exif.Entry
+	}{
+		{GroupEXIF, exiftag.Tiff, x.IFD0},
+		{GroupEXIF, exiftag.Exif, x.Exif},
+		{GroupGPS, exiftag.GPS, x.GPS},
+		{GroupEXIF, exiftag.Interop, x.Interop},
+	} {
+		for _, e := range d.ifd {
+			if e.Tag == 0x8769 || e.Tag == 0x8825 || e.Tag == 0xa005 {
+				continue // sub-IFD offsets
+			}
+			name := exiftag.Id(d.dir | uint32(e.Tag))
+			if name == "" {
+				name = fmt.Sprintf("Tag 0x%04x", e.Tag)
+			}
+			fields = append(fields, MetaField{d.group, name, formatEntry(x, d.dir|uint32(e.Tag))})
+		}
+	}
+	if len(x.Thumb) > 0 {
+		fields = append(fields, MetaField{GroupEXIF, "Thumbnail", formatFileSize(int64(len(x.Thumb)))})
+	}
+	return fields
+}
+
+// readable value of EXIF entry
+func formatEntry(x *exif.Exif, name uint32) string {
+	t := x.Tag(name)
+	values := []string{}
+	switch t.Type() {
+	case exif.TypeAscii:
+		s, _ := t.Ascii()
+		return truncate(strings.Trim(s, " \x00"))
+	case exif.TypeShort:
+		for _, v := range t.Short() {
+			values = append(values, strconv.Itoa(int(v)))
+		}
+	case exif.TypeLong:
+		for _, v := range t.Long() {
+			values = append(values, strconv.FormatUint(uint64(v), 10))
+		}
+	case exif.TypeSLong:
+		for _, v := range t.SLong() {
+			values = append(values, strconv.Itoa(int(v)))
+		}
+	case exif.TypeRational:
+		r := t.Rational()
+		for i := 0; i+1 < len(r); i += 2 {
+			values = append(values, fmt.Sprintf("%d/%d", r[i], r[i+1]))
+		}
+	case exif.TypeSRational:
+		r := t.SRational()
+		for i := 0; i+1 < len(r); i += 2 {
+			values = append(values, fmt.Sprintf("%d/%d", r[i], r[i+1]))
+		}
+	default:
+		p := t.E.Value
+		if isPrintable(p) {
+			return truncate(strings.Trim(string(p), " \x00"))
+		}
+		if len(p) > 32 {
+			return fmt.Sprintf("% x … (%d bytes)", p[:32], len(p))
+		}
+		return fmt.Sprintf("% x", p)
+	}
+	return truncate(strings.Join(values, " "))
+}
+
+func isPrintable(p []byte) bool {
+	for _, b := range bytes.TrimRight(p, "\x00") {
+		if b < 0x20 || b > 0x7e {
+			return false
+		}
+	}
+	return len(p) > 0
+}
+
+func truncate(s string) string {
+	if len(s) > maxFieldValueLen {
+		return s[:maxFieldValueLen] + "…"
+	}
+	return s
+}
+
+// rational tag value as float
+func rationalValue(x *exif.Exif, name uint32) (float64, bool) {
+	t := x.Tag(name)
+	if r := t.Rational(); len(r) >= 2 && r[1] != 0 {
+		return float64(r[0]) / float64(r[1]), true
+	}
+	if r := t.SRational(); len(r) >= 2 && r[1] != 0 {
+		return float64(r[0]) / float64(r[1]), true
+	}
+	return 0, false
+}
+
+func formatExposureTime(x *exif.Exif) string {
+	v, ok := rationalValue(x, exiftag.ExposureTime)
+	if !ok || v <= 0 {
+		return ""
+	}
+	if v < 0.5 {
+		return fmt.Sprintf("1/%.0f s", 1/v)
+	}
+	return strconv.FormatFloat(v, 'f', -1, 64) + " s"
+}
+
+func formatAperture(x *exif.Exif) string {
+	v, ok := rationalValue(x, exiftag.FNumber)
+	if !ok || v <= 0 {
+		return ""
+	}
+	return "f/" + strconv.FormatFloat(v, 'f', 1, 64)
+}
+
+func formatISO(x *exif.Exif) string {
+	if v := x.Tag(exiftag.ISOSpeedRatings).Short(); len(v) > 0 {
+		return "ISO " + strconv.Itoa(int(v[0]))
+	}
+	return ""
+}
+
+func formatFocalLength(x *exif.Exif) string {
+	v, ok := rationalValue(x, exiftag.FocalLength)
+	if !ok || v <= 0 {
+		return ""
+	}
+	return strconv.FormatFloat(v, 'f', -1, 64) + " mm"
+}
+
+func formatExposureBias(x *exif.Exif) string {
+	v, ok := rationalValue(x, exiftag.ExposureBiasValue)
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf("%+.1f EV", v)
+}
+
+func formatLatLong(lat, long float64) string {
+	return fmt.Sprintf("%.6f, %.6f", lat, long)
+}
+
+func formatFileSize(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", n)
+}
+
+// XMP properties as prefixed fields, array items are joined
+func xmpFields(packet []byte) []MetaField {
+	values, prefixes := xmpProperties(packet)
+	names := map[string]xml.Name{}
+	keys := []string{}
+	for n := range values {
+		key := n.Local
+		if p, ok := prefixes[n.Space]; ok {
+			key = p + ":" + n.Local
+		}
+		names[key] = n
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	fields := []MetaField{}
+	for _, k := range keys {
+		fields = append(fields, MetaField{GroupXMP, k, truncate(strings.Join(values[names[k]], "; "))})
+	}
+	return fields
+}
@@ -0,0 +1,299 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/tajtiattila/metadata/exif"
+	"github.com/tajtiattila/metadata/exif/exiftag"
+)
+
+var errPatchVerify = errors.New("patched file verification failed")
+
+// Byte range replacement
+type bytePatch struct {
+	Offset int
+	Value  []byte
+}
+
+// TIFF IFD entry location within JPEG data
+type ifdEntry struct {
+	Type        uint16
+	Count       uint32
+	ValueOffset int // absolute offset of value bytes
+}
+
+// EXIF APP1 segment structure with entries of IFD0 and Exif sub-IFD
+type exifLayout struct {
+	bo      binary.ByteOrder
+	entries map[uint32]ifdEntry // keyed by exiftag name (dir | tag)
+}
+
+// locate EXIF entries in JPEG data
+func parseExifLayout(data []byte) (*exifLayout, error) {
+	segs, _, err := splitJpeg(data)
+	if err != nil {
+		return nil, err
+	}
+	i := findSegment(segs, markerAPP1, []byte("Exif\x00\x00"))
+	if i < 0 {
+		return nil, exif.NotFound
+	}
+	start := 0
+	for _, s := range segs[:i] {
+		start += len(s)
+	}
+	seg := segs[i]
+	base := start + 10 // TIFF header after marker, length and "Exif\0\0"
+	tiff := seg[10:]
+	if len(tiff) < 8 {
+		return nil, exif.ErrCorruptHeader
+	}
+	l := &exifLayout{entries: map[uint32]ifdEntry{}}
+	switch string(tiff[:2]) {
+	case "II":
+		l.bo = binary.LittleEndian
+	case "MM":
+		l.bo = binary.BigEndian
+	default:
+		return nil, exif.ErrCorruptHeader
+	}
+	readIFD := func(dir uint32, ofs int) error {
+		if ofs < 8 || ofs+2 > len(tiff) {
+			return exif.ErrCorruptHeader
+		}
+		n := int(l.bo.Uint16(tiff[ofs:]))
+		if ofs+2+n*12 > len(tiff) {
+			return exif.ErrCorruptHeader
+		}
+		for k := 0; k < n; k++ {
+			e := tiff[ofs+2+k*12:]
+			entry := ifdEntry{Type: l.bo.Uint16(e[2:]), Count: l.bo.Uint32(e[4:])}
+			size := int(entry.Count) * typeSize(entry.Type)
+			voff := ofs + 2 + k*12 + 8
+			if size > 4 {
+				voff = int(l.bo.Uint32(e[8:]))
+			}
+			if size < 0 || voff+size > len(tiff) {
+				continue
+			}
+			entry.ValueOffset = base + voff
+			l.entries[dir|uint32(l.bo.Uint16(e))] = entry
+		}
+		return nil
+	}
+	if err := readIFD(exiftag.Tiff, int(l.bo.Uint32(tiff[4:]))); err != nil {
+		return nil, err
+	}
+	if e, ok := l.entries[exiftag.Tiff|0x8769]; ok && e.Type == exif.TypeLong {
+		ofs := int(l.bo.Uint32(data[e.ValueOffset:]))
+		if err := readIFD(exiftag.Exif, ofs); err != nil {
+			return nil, err
+		}
+	}
+	return l, nil
+}
+
+// size of TIFF value type
+func typeSize(t uint16) int {
+	switch t {
+	case exif.TypeByte, exif.TypeAscii, exif.TypeSByte, exif.TypeUndef:
+		return 1
+	case exif.TypeShort, exif.TypeSShort:
+		return 2
+	case exif.TypeLong, exif.TypeSLong, exif.TypeFloat:
+		return 4
+	case exif.TypeRational, exif.TypeSRational, exif.TypeDouble:
+		return 8
+	}
+	return 0
+}
+
+// patch of ASCII tag value with NUL terminated value of the same length, ok is false if tag is missing or length differs
+func (l *exifLayout) asciiPatch(tag uint32, value string) (p bytePatch, ok bool) {
+	e, found := l.entries[tag]
+	if !found || e.Type != exif.TypeAscii || int(e.Count) != len(value)+1 {
+		return bytePatch{}, false
+	}
+	return bytePatch{Offset: e.ValueOffset, Value: append([]byte(value), 0)}, true
+}
+
+// sub-second digits fitting existing tag length, ok is false if tag is missing and date needs sub-seconds
+// or tag exists and date has none, as setExifDate removes it then
+func (l *exifLayout) subSecPatch(tag uint32, d Date) (p bytePatch, ok bool) {
+	e, found := l.entries[tag]
+	if !found || !d.SubSec {
+		return bytePatch{}, !found && !d.SubSec
+	}
+	if e.Type != exif.TypeAscii || e.Count < 2 {
+		return bytePatch{}, false
+	}
+	digits := fmt.Sprintf("%09d", d.Nanosecond())
+	n := int(e.Count) - 1
+	if n > len(digits) {
+		digits += strings.Repeat("0", n-len(digits))
+	}
+	if strings.TrimRight(digits[n:], "0") != "" {
+		return bytePatch{}, false // existing tag is too short for the precision
+	}
+	return bytePatch{Offset: e.ValueOffset, Value: append([]byte(digits[:n]), 0)}, true
+}
+
+// patches of date tags selected by tags mask, ok is false if any of them can't be patched in place
+func exifDatePatches(data []byte, d Date, tags int) (patches []bytePatch, ok bool) {
+	l, err := parseExifLayout(data)
+	if err != nil {
+		return nil, false
+	}
+	for _, t := range []struct {
+		mask                        int
+		dateTag, subSecTag, offsTag uint32
+	}{
+		{WriteDateTime, exiftag.DateTime, exiftag.SubSecTime, OffsetTime},
+		{WriteDateTimeOriginal, exiftag.DateTimeOriginal, exiftag.SubSecTimeOriginal, OffsetTimeOriginal},
+		{WriteDateTimeDigitized, exiftag.DateTimeDigitized, exiftag.SubSecTimeDigitized, OffsetTimeDigitized},
+	} {
+		if tags&t.mask == 0 {
+			continue
+		}
+		p, ok := l.asciiPatch(t.dateTag, d.Format(DateFormat))
+		if !ok {
+			return nil, false
+		}
+		patches = append(patches, p)
+		p, ok = l.subSecPatch(t.subSecTag, d)
+		if !ok {
+			return nil, false
+		}
+		if p.Value != nil {
+			patches = append(patches, p)
+		}
+		if d.HasOffset {
+			p, ok = l.asciiPatch(t.offsTag, d.Format(OffsetFormat))
+			if !ok {
+				return nil, false
+			}
+			patches = append(patches, p)
+		}
+	}
+	if tags&WriteXMPDateCreated != 0 {
+		p, ok := xmpDatePatch(data, d)
+		if !ok {
+			return nil, false
+		}
+		patches = append(patches, p)
+	}
+	return patches, true
+}
+
+// patch of XMP photoshop:DateCreated value of the same length
+func xmpDatePatch(data []byte, d Date) (p bytePatch, ok bool) {
+	segs, _, err := splitJpeg(data)
+	if err != nil {
+		return bytePatch{}, false
+	}
+	i := findSegment(segs, markerAPP1, xmpPrefix)
+	if i < 0 {
+		return bytePatch{}, false
+	}
+	start := 4 + len(xmpPrefix)
+	for _, s := range segs[:i] {
+		start += len(s)
+	}
+	packet := string(segs[i][4+len(xmpPrefix):])
+	prefix, declared := xmpDateCreated.prefix(packet)
+	if !declared {
+		return bytePatch{}, false
+	}
+	q := regexp.QuoteMeta(prefix + ":" + xmpDateCreated.Local)
+	re := regexp.MustCompile(`\s` + q + `\s*=\s*"([^"]*)"|\s` + q + `\s*=\s*'([^']*)'|<` + q + `>([^<]*)</` + q + `>`)
+	m := re.FindStringSubmatchIndex(packet)
+	if m == nil {
+		return bytePatch{}, false
+	}
+	value := d.XMPString()
+	for g := 2; g < len(m); g += 2 {
+		if m[g] >= 0 {
+			if m[g+1]-m[g] != len(value) {
+				return bytePatch{}, false
+			}
+			return bytePatch{Offset: start + m[g], Value: []byte(value)}, true
+		}
+	}
+	return bytePatch{}, false
+}
+
+// apply patches to a copy of data
+func applyPatches(data []byte, patches []bytePatch) []byte {
+	out := append([]byte{}, data...)
+	for _, p := range patches {
+		copy(out[p.Offset:], p.Value)
+	}
+	return out
+}
+
+// check that patched data differs from original only by patched bytes
+func verifyPatches(original, out []byte, patches []bytePatch) error {
+	if len(original) != len(out) {
+		return errPatchVerify
+	}
+	patched := make([]bool, len(original))
+	for _, p := range patches {
+		if p.Offset < 0 || p.Offset+len(p.Value) > len(out) || !bytes.Equal(out[p.Offset:p.Offset+len(p.Value)], p.Value) {
+			return errPatchVerify
+		}
+		for i := range p.Value {
+			patched[p.Offset+i] = true
+		}
+	}
+	for i := range original {
+		if !patched[i] && original[i] != out[i] {
+			return errPatchVerify
+		}
+	}
+	return nil
+}
+
+// rewrite date values in place keeping every other byte of the file,
+// patched is false if the tags can't be patched without changing value lengths
+func patchExifDate(file, backupDirName string, date Date, tags int) (patched bool, err error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return false, err
+	}
+	patches, ok := exifDatePatches(data, date, tags)
+	if !ok {
+		return false, nil
+	}
+	out := applyPatches(data, patches)
+	if err = verifyPatches(data, out, patches); err != nil {
+		return false, err
+	}
+	bak := filepath.Join(backupDirName, filepath.Base(file))
+	err = os.Rename(file, bak)
+	if err != nil {
+		return false, err
+	}
+	err = os.WriteFile(file, out, 0664)
+	if err != nil {
+		return true, err
+	}
+	// read back catches short or failed writes only, the data likely comes from page cache
+	written, err := os.ReadFile(file)
+	if err != nil {
+		return true, err
+	}
+	if !bytes.Equal(written, out) {
+		if err := os.WriteFile(file, data, 0664); err != nil {
+			return true, fmt.Errorf("%w, restore original: %v", errPatchVerify, err)
+		}
+		return true, errPatchVerify
+	}
+	return true, nil
+}
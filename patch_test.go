@@ -0,0 +1,101 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tajtiattila/metadata/exif"
+)
+
+// EXIF with dates, optional sub-seconds and offsets
+func testDateExif(nsec int, offset string) *exif.Exif {
+	x := exif.New(16, 16)
+	x.SetDateTime(time.Date(2020, 1, 2, 3, 4, 5, nsec, time.Local))
+	if offset != "" {
+		for _, tag := range []uint32{OffsetTime, OffsetTimeOriginal, OffsetTimeDigitized} {
+			x.Set(tag, exif.Ascii(offset))
+		}
+	}
+	return x
+}
+
+func TestExifDatePatches(t *testing.T) {
+	tests := []struct {
+		name    string
+		x       *exif.Exif
+		date    string
+		tags    int
+		patches int // number of patches, -1 if date can't be patched in place
+	}{
+		{"dates", testDateExif(0, ""), "2023:05:14 15:16:17", DefaultDateTags, 3},
+		{"DateTime only", testDateExif(0, ""), "2023:05:14 15:16:17", WriteDateTime, 1},
+		{"no sub-second tags", testDateExif(0, ""), "2023:05:14 15:16:17.5", DefaultDateTags, -1},
+		{"no offset tags", testDateExif(0, ""), "2023:05:14 15:16:17+02:00", DefaultDateTags, -1},
+		{"offsets", testDateExif(0, "+01:00"), "2023:05:14 15:16:17+02:00", DefaultDateTags, 6},
+		{"sub-seconds", testDateExif(120e6, ""), "2023:05:14 15:16:17.340", DefaultDateTags, 6},
+		{"sub-seconds too precise", testDateExif(120e6, ""), "2023:05:14 15:16:17.345", DefaultDateTags, -1},
+		{"sub-seconds removed", testDateExif(120e6, ""), "2023:05:14 15:16:17", DefaultDateTags, -1},
+		{"no XMP", testDateExif(0, ""), "2023:05:14 15:16:17", DefaultDateTags | WriteXMPDateCreated, -1},
+		{"no EXIF", nil, "2023:05:14 15:16:17", DefaultDateTags, -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := parseDate(tt.date)
+			if err != nil {
+				t.Fatal(err)
+			}
+			data := testJpeg(t, tt.x)
+			patches, ok := exifDatePatches(data, d, tt.tags)
+			if !ok {
+				if tt.patches >= 0 {
+					t.Fatalf("can't patch, want %d patches", tt.patches)
+				}
+				return
+			}
+			if tt.patches < 0 || len(patches) != tt.patches {
+				t.Fatalf("%d patches, want %d", len(patches), tt.patches)
+			}
+			out := applyPatches(data, patches)
+			if err := verifyPatches(data, out, patches); err != nil {
+				t.Fatal(err)
+			}
+			got, _ := getExifDate(readTestExif(t, out))
+			if tt.tags&WriteDateTimeOriginal == 0 {
+				return // date is read from DateTimeOriginal
+			}
+			if !got.Equal(d.Time) || got.HasOffset != d.HasOffset {
+				t.Errorf("date = %v, want %v", got, d)
+			}
+		})
+	}
+}
+
+func TestVerifyPatches(t *testing.T) {
+	original := []byte("0123456789")
+	patches := []bytePatch{{Offset: 2, Value: []byte("ab")}, {Offset: 7, Value: []byte("c")}}
+	tests := []struct {
+		name    string
+		out     string
+		patches []bytePatch
+		ok      bool
+	}{
+		{"patched", "01ab456c89", patches, true},
+		{"no patches", "0123456789", nil, true},
+		{"patch missing", "01ab456789", patches, false},
+		{"other byte changed", "01ab456c8x", patches, false},
+		{"shorter", "01ab456c8", patches, false},
+		{"longer", "01ab456c890", patches, false},
+		{"patch out of range", "0123456789", []bytePatch{{Offset: 9, Value: []byte("xy")}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := verifyPatches(original, []byte(tt.out), tt.patches)
+			if (err == nil) != tt.ok {
+				t.Errorf("error = %v, want ok %v", err, tt.ok)
+			}
+		})
+	}
+	if out := applyPatches(original, patches); string(out) != "01ab456c89" || string(original) != "0123456789" {
+		t.Errorf("applyPatches = %q, original %q", out, original)
+	}
+}
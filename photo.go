@@ -4,10 +4,12 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"time"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/canvas"
 	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 	"github.com/disintegration/imaging"
 )
@@ -17,22 +19,50 @@ const (
 	ChoiceExifDate = iota
 	ChoiceFileDate
 	ChoiceEnteredDate
+	ChoiceNameDate
 )
-const DateFormat = "2006:01:02 03:04:05"
 
 // Photo
 type Photo struct {
-	File       string
-	Droped     bool
-	Img        *canvas.Image
-	Dates      [3]string
-	DateChoice int
+	File           string
+	Droped         bool
+	Img            *canvas.Image
+	Dates          [4]Date
+	DateChoice     int
+	DateInvalid    bool
+	ExifDateSource string
+	Camera         string
+	CameraSerial   string
+	Shooting       ShootingInfo
+	Rating         int    // 0 for no rating, 1 to 5 stars
+	Label          string // color label
+	Title          string
+	Caption        string
+	Keywords       []string
+	GPS            *GeoPoint // nil if position is unknown
+
+	invalidDate string // entered text while DateInvalid
+	exifOffset  bool   // EXIF date has offset tag
+
+	descriptionChanged bool // title, caption or keywords edited
+	gpsChanged         bool // position matched from track
+	sharedBase         bool // another file of the folder has the same name without extension
 }
 
 // frame column that contains button with photo image as background and date fix input
 func (p *Photo) FrameColumn() *fyne.Container {
 	fileLabel := widget.NewLabelWithStyle(filepath.Base(p.File), fyne.TextAlignCenter, fyne.TextStyle{Bold: true})
-	column := container.NewBorder(fileLabel, p.dateInput(), nil, nil, p.imgButton())
+	infoBtn := widget.NewButtonWithIcon("", theme.InfoIcon(), func() { pl.inspectPhoto(p) })
+	infoBtn.Importance = widget.LowImportance
+	top := container.NewVBox(container.NewBorder(nil, nil, nil, container.NewHBox(p.describeButton(), infoBtn), fileLabel))
+	if caption := p.Shooting.caption(shootingInfoPref()); caption != "" {
+		top.Add(widget.NewLabelWithStyle(caption, fyne.TextAlignCenter, fyne.TextStyle{Italic: true}))
+	}
+	bottom := container.NewVBox(p.dateInput())
+	if sidecarModePref() {
+		bottom.Add(p.ratingInput())
+	}
+	column := container.NewBorder(top, bottom, nil, nil, p.imgButton())
 	return column
 }
 
@@ -64,30 +94,52 @@ func (p *Photo) imgButton() *fyne.Container {
 func (p *Photo) dateInput() *fyne.Container {
 	d := p.Dates[p.DateChoice]
 
-	eDate := widget.NewEntry()
-	eDate.SetText(d)
+	var eDate *DatePicker
+	eDate = newDatePicker(d, func(d Date, valid bool) {
+		if p.DateChoice != ChoiceEnteredDate {
+			return
+		}
+		p.DateInvalid = !valid
+		if valid {
+			p.Dates[ChoiceEnteredDate] = d
+		} else {
+			p.invalidDate = eDate.Text()
+		}
+	})
 	eDate.Disable()
 
 	rgDateChoice := widget.NewRadioGroup(
-		[]string{"EXIF", "File", "Input"},
+		[]string{"EXIF", "File", "Name", "Input"},
 		func(s string) {
 			switch s {
 			case "EXIF":
-				p.Dates[ChoiceEnteredDate] = ""
+				p.Dates[ChoiceEnteredDate] = Date{}
 				p.DateChoice = ChoiceExifDate
-				eDate.SetText(p.Dates[p.DateChoice])
+				p.DateInvalid = false
+				eDate.SetDate(p.Dates[p.DateChoice])
 				eDate.Disable()
 			case "File":
-				p.Dates[ChoiceEnteredDate] = ""
+				p.Dates[ChoiceEnteredDate] = Date{}
 				p.DateChoice = ChoiceFileDate
-				eDate.SetText(p.Dates[p.DateChoice])
+				p.DateInvalid = false
+				eDate.SetDate(p.Dates[p.DateChoice])
+				eDate.Disable()
+			case "Name":
+				p.Dates[ChoiceEnteredDate] = Date{}
+				p.DateChoice = ChoiceNameDate
+				p.DateInvalid = false
+				eDate.SetDate(p.Dates[p.DateChoice])
 				eDate.Disable()
 			case "Input":
-				p.DateChoice = ChoiceEnteredDate
-				if p.Dates[p.DateChoice] == "" {
-					p.Dates[p.DateChoice] = p.Dates[ChoiceExifDate]
+				if p.Dates[ChoiceEnteredDate].IsZero() {
+					// seed with the date chosen so far or today if it is not set
+					p.Dates[ChoiceEnteredDate] = p.Dates[p.DateChoice]
+					if p.Dates[ChoiceEnteredDate].IsZero() {
+						p.Dates[ChoiceEnteredDate] = newDate(time.Now(), false, false)
+					}
 				}
-				eDate.SetText(p.Dates[p.DateChoice])
+				p.DateChoice = ChoiceEnteredDate
+				eDate.SetDate(p.Dates[p.DateChoice])
 				eDate.Enable()
 			}
 		})
@@ -96,12 +148,17 @@ func (p *Photo) dateInput() *fyne.Container {
 		rgDateChoice.SetSelected("EXIF")
 	case ChoiceFileDate:
 		rgDateChoice.SetSelected("File")
+	case ChoiceNameDate:
+		rgDateChoice.SetSelected("Name")
 	case ChoiceEnteredDate:
 		rgDateChoice.SetSelected("Input")
 	}
 	rgDateChoice.Horizontal = true
+	if p.DateInvalid {
+		eDate.SetText(p.invalidDate)
+	}
 
-	gr := container.NewVBox(rgDateChoice, eDate)
+	gr := container.NewVBox(rgDateChoice, container.NewGridWrap(fyne.NewSize(260, eDate.MinSize().Height), eDate))
 
 	return container.NewCenter(gr)
 }
@@ -124,12 +181,33 @@ func (p *Photo) img(scale int) (img *canvas.Image) {
 	return
 }
 
-// get file modify date string
-func (p *Photo) getModifyDate() string {
+// camera identity: make, model and serial number if known
+func (p *Photo) CameraID() string {
+	switch {
+	case p.CameraSerial == "":
+		return p.Camera
+	case p.Camera == "":
+		return "#" + p.CameraSerial
+	}
+	return p.Camera + " #" + p.CameraSerial
+}
+
+// EXIF date if set, name date or file date otherwise
+func (p *Photo) defaultDateChoice() int {
+	switch {
+	case !p.Dates[ChoiceExifDate].IsZero():
+		return ChoiceExifDate
+	case !p.Dates[ChoiceNameDate].IsZero():
+		return ChoiceNameDate
+	}
+	return ChoiceFileDate
+}
+
+// get file modify date
+func (p *Photo) getModifyDate() Date {
 	fi, err := os.Stat(p.File)
 	if err != nil {
-		return ""
+		return Date{}
 	}
-	fileModifyDate := fi.ModTime()
-	return fileModifyDate.Format(DateFormat)
+	return newDate(fi.ModTime().Local(), false, false)
 }
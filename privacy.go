@@ -0,0 +1,423 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/tajtiattila/metadata/exif/exiftag"
+)
+
+// preference keys of privacy profile
+const (
+	prefPrivacyTags   = "privacyTags"
+	prefPrivacyOnSave = "privacyOnSave"
+)
+
+// Metadata groups removed by privacy profile
+const (
+	StripGPS = 1 << iota
+	StripMakerNotes
+	StripOwner
+	StripThumbnail
+)
+
+const DefaultPrivacyTags = StripGPS | StripOwner
+
+var privacyItems = []struct {
+	group int
+	name  string
+}{
+	{StripGPS, "GPS position"},
+	{StripMakerNotes, "Maker notes"},
+	{StripOwner, "Owner and serial numbers"},
+	{StripThumbnail, "Thumbnail"},
+}
+
+// EXIF tags of owner and serial numbers
+var ownerTags = map[uint32]string{
+	exiftag.Exif | 0xa430: "CameraOwnerName",
+	BodySerialNumber:      "BodySerialNumber",
+	exiftag.Exif | 0xa435: "LensSerialNumber",
+	exiftag.Tiff | 0xc62f: "CameraSerialNumber",
+}
+
+const nsAux = "http://ns.adobe.com/exif/1.0/aux/"
+
+// XMP properties of owner and serial numbers
+var xmpOwnerNames = []XMPName{
+	{"aux", nsAux, "SerialNumber"},
+	{"aux", nsAux, "LensSerialNumber"},
+	{"aux", nsAux, "OwnerName"},
+}
+
+// XMP properties of GPS position
+var xmpGPSNames = []XMPName{
+	xmpGPSLatitude, xmpGPSLongitude,
+	{"exif", nsEXIF, "GPSAltitude"}, {"exif", nsEXIF, "GPSAltitudeRef"},
+	{"exif", nsEXIF, "GPSTimeStamp"}, {"exif", nsEXIF, "GPSVersionID"},
+}
+
+// metadata groups to remove
+func privacyTagsPref() int {
+	return fyne.CurrentApp().Preferences().IntWithFallback(prefPrivacyTags, DefaultPrivacyTags)
+}
+
+// remove metadata groups selected by mask from EXIF segment, return descriptions of removed data;
+// removed entries and their values are zeroed, all other bytes of the segment are kept
+func stripExif(seg []byte, groups int) ([]byte, []string, error) {
+	t, err := newTiffEditor(seg)
+	if err != nil {
+		return nil, nil, err
+	}
+	ifd0, next, err := t.readIFD(t.ifd0())
+	if err != nil {
+		return nil, nil, err
+	}
+	exifIFD, _ := t.subIFD(ifd0, tagExifIFD)
+	removed := []string{}
+	if gps, _ := t.subIFD(ifd0, tagGPSIFD); groups&StripGPS != 0 && gps > 0 {
+		entries, _, err := t.readIFD(gps)
+		if err != nil {
+			return nil, nil, err
+		}
+		removed = append(removed, fmt.Sprintf("GPS (%d tags)", len(entries)))
+		t.zeroIFD(gps)
+		if _, err = t.removeEntries(t.ifd0(), func(e tiffEntry) bool { return e.Tag == tagGPSIFD }); err != nil {
+			return nil, nil, err
+		}
+	}
+	if groups&StripMakerNotes != 0 && exifIFD > 0 {
+		r, err := t.removeEntries(exifIFD, func(e tiffEntry) bool { return e.Tag == tagMakerNote })
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, e := range r {
+			removed = append(removed, "MakerNote "+formatFileSize(int64(e.Count)*int64(typeSize(e.Type))))
+		}
+	}
+	if groups&StripOwner != 0 {
+		for _, dir := range []struct {
+			dir uint32
+			ofs int
+		}{{exiftag.Tiff, t.ifd0()}, {exiftag.Exif, exifIFD}} {
+			if dir.ofs == 0 {
+				continue
+			}
+			values := map[uint16]string{}
+			r, err := t.removeEntries(dir.ofs, func(e tiffEntry) bool {
+				if _, ok := ownerTags[dir.dir|uint32(e.Tag)]; ok {
+					values[e.Tag] = strings.Trim(string(t.value(e)), " \x00")
+					return true
+				}
+				return false
+			})
+			if err != nil {
+				return nil, nil, err
+			}
+			for _, e := range r {
+				removed = append(removed, ownerTags[dir.dir|uint32(e.Tag)]+" "+values[e.Tag])
+			}
+		}
+	}
+	if groups&StripThumbnail != 0 && next > 0 {
+		ifd1, _, err := t.readIFD(next)
+		if err != nil {
+			return nil, nil, err
+		}
+		ofs, size := 0, 0
+		for _, e := range ifd1 {
+			switch e.Tag {
+			case tagThumbOfs:
+				ofs = int(t.bo.Uint32(e.Value[:]))
+			case tagThumbBytes:
+				size = int(t.bo.Uint32(e.Value[:]))
+			}
+		}
+		if size > 0 && ofs >= 8 && ofs+size <= len(t.tiff) {
+			removed = append(removed, "Thumbnail "+formatFileSize(int64(size)))
+			t.zeroIFD(next)
+			t.zero(ofs, size)
+			if ofs+size == len(t.tiff) {
+				t.tiff = t.tiff[:ofs]
+			}
+			entries, _, _ := t.readIFD(t.ifd0())
+			t.bo.PutUint32(t.tiff[t.ifd0()+2+len(entries)*12:], 0)
+		}
+	}
+	if len(removed) == 0 {
+		return seg, nil, nil
+	}
+	seg, err = t.segment()
+	return seg, removed, err
+}
+
+// remove metadata groups selected by mask from XMP packet
+func stripXMP(packet string, groups int) (string, []string) {
+	removed := []string{}
+	names := []XMPName{}
+	if groups&StripGPS != 0 {
+		names = append(names, xmpGPSNames...)
+	}
+	if groups&StripOwner != 0 {
+		names = append(names, xmpOwnerNames...)
+	}
+	for _, n := range names {
+		if p := xmpRemove(packet, n); p != packet {
+			removed = append(removed, "XMP "+n.Local)
+			packet = p
+		}
+	}
+	return packet, removed
+}
+
+// remove metadata groups from JPEG data keeping dates, orientation and all other tags,
+// data is returned unchanged if there is nothing to remove
+func sanitizeJpeg(data []byte, groups int) ([]byte, []string, error) {
+	segs, rest, err := splitJpeg(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	removed := []string{}
+	if i := findSegment(segs, markerAPP1, exifPrefix); i >= 0 {
+		seg, r, err := stripExif(segs[i], groups)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(r) > 0 {
+			removed = append(removed, r...)
+			segs[i] = seg
+			data = joinJpeg(segs, rest)
+		}
+	}
+	if i := findSegment(segs, markerAPP1, xmpPrefix); i >= 0 {
+		_, r := stripXMP(string(segs[i][4+len(xmpPrefix):]), groups)
+		if len(r) > 0 {
+			removed = append(removed, r...)
+			data, err = updateJpegXMP(data, func(packet string) string {
+				packet, _ = stripXMP(packet, groups)
+				return packet
+			})
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+	return data, removed, nil
+}
+
+// metadata the privacy profile would remove from photo file
+func privacyRemovals(file string, groups int) []string {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return []string{err.Error()}
+	}
+	_, removed, err := sanitizeJpeg(data, groups)
+	if err != nil {
+		return []string{err.Error()}
+	}
+	return removed
+}
+
+// strip metadata of photo file, false if there was nothing to remove;
+// file is moved to backup folder, created if needed, unless backedUp tells it was done already
+func sanitizePhoto(file, backupDirName string, backedUp bool, groups int) (bool, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return false, err
+	}
+	out, removed, err := sanitizeJpeg(data, groups)
+	if err != nil || len(removed) == 0 {
+		return false, err
+	}
+	if !backedUp {
+		if err = os.MkdirAll(backupDirName, 0775); err != nil {
+			return false, err
+		}
+		if err = os.Rename(file, filepath.Join(backupDirName, filepath.Base(file))); err != nil {
+			return false, err
+		}
+	}
+	return true, os.WriteFile(file, out, 0664)
+}
+
+// copy not dropped photos with stripped metadata to folder calling next before each file, stop when next returns false
+func (l *PhotoList) exportSanitized(folder string, groups int) func(next func(i int, p *Photo) bool) *SaveReport {
+	return func(next func(i int, p *Photo) bool) *SaveReport {
+		r := &SaveReport{Total: len(l.List)}
+		for i, p := range l.List {
+			if !next(i, p) {
+				r.Canceled = true
+				break
+			}
+			r.Done++
+			if p.Droped {
+				continue
+			}
+			data, err := os.ReadFile(p.File)
+			if err != nil {
+				r.fail(p, err)
+				continue
+			}
+			data, removed, err := sanitizeJpeg(data, groups)
+			if err != nil {
+				r.fail(p, err)
+				continue
+			}
+			target := filepath.Join(folder, filepath.Base(p.File))
+			if _, err := os.Stat(target); err == nil {
+				r.fail(p, fmt.Errorf("%w in export folder", fs.ErrExist))
+				continue
+			} else if !errors.Is(err, fs.ErrNotExist) {
+				r.fail(p, err)
+				continue
+			}
+			if err = os.WriteFile(target, data, 0664); err != nil {
+				r.fail(p, err)
+				continue
+			}
+			r.Exported++
+			if len(removed) > 0 {
+				r.Sanitized++
+			}
+		}
+		return r
+	}
+}
+
+// show privacy profile dialog with preview of metadata to remove and export of sanitized copies
+func (l *PhotoList) privacyPreview() {
+	type removal struct {
+		photo   *Photo
+		removed string
+	}
+	removals := []removal{}
+	titles := []string{"File Name", "Removed"}
+	table := widget.NewTable(
+		func() (int, int) { return len(removals) + 1, len(titles) },
+		func() fyne.CanvasObject { return widget.NewLabel(DateTemplate) },
+		func(i widget.TableCellID, o fyne.CanvasObject) {
+			label := o.(*widget.Label)
+			label.TextStyle.Bold = i.Row == 0
+			switch {
+			case i.Row == 0:
+				label.SetText(titles[i.Col])
+			case i.Col == 0:
+				label.SetText(filepath.Base(removals[i.Row-1].photo.File))
+			default:
+				label.SetText(removals[i.Row-1].removed)
+			}
+		})
+	table.SetColumnWidth(1, 560)
+	summary := widget.NewLabel("")
+	// files are checked in background, results of outdated checks are dropped
+	var generation atomic.Int64
+	update := func() {
+		gen := generation.Add(1)
+		groups := privacyTagsPref()
+		photos := append([]*Photo{}, l.List...)
+		summary.SetText("Checking photos...")
+		go func() {
+			found := []removal{}
+			for _, p := range photos {
+				if generation.Load() != gen {
+					return
+				}
+				if p.Droped {
+					continue
+				}
+				if r := privacyRemovals(p.File, groups); len(r) > 0 {
+					found = append(found, removal{p, strings.Join(r, ", ")})
+				}
+			}
+			if generation.Load() != gen {
+				return
+			}
+			removals = found
+			summary.SetText(fmt.Sprintf("Metadata will be removed from %d photos", len(removals)))
+			table.Refresh()
+		}()
+	}
+	s := &Settings{}
+	groups := s.privacyTagsRow()
+	chosen := groups.OnChanged
+	groups.OnChanged = func(selected []string) {
+		chosen(selected)
+		update()
+	}
+	update()
+
+	export := widget.NewButton("Export sanitized copies...", func() {
+		dialog.ShowFolderOpen(func(u fyne.ListableURI, err error) {
+			if err != nil || u == nil {
+				return
+			}
+			l.runInBackground("Exporting", l.exportSanitized(u.Path(), privacyTagsPref()))
+		}, wMain)
+	})
+	top := container.NewVBox(groups, s.privacyOnSaveRow(), summary)
+	content := container.NewBorder(top, export, nil, nil, table)
+	dlg := dialog.NewCustom("Privacy", "Close", content, wMain)
+	dlg.SetOnClosed(func() { generation.Add(1) })
+	dlg.Resize(fyne.NewSize(900, 600))
+	dlg.Show()
+}
+
+func (s *Settings) privacyTagsRow() *widget.CheckGroup {
+	groups := privacyTagsPref()
+	names := []string{}
+	selected := []string{}
+	for _, item := range privacyItems {
+		names = append(names, item.name)
+		if groups&item.group != 0 {
+			selected = append(selected, item.name)
+		}
+	}
+	group := widget.NewCheckGroup(names, nil)
+	group.Horizontal = true
+	group.SetSelected(selected)
+	group.OnChanged = s.choosePrivacyTags
+	return group
+}
+
+func (s *Settings) choosePrivacyTags(selected []string) {
+	groups := 0
+	for _, item := range privacyItems {
+		for _, name := range selected {
+			if name == item.name {
+				groups |= item.group
+			}
+		}
+	}
+	fyne.CurrentApp().Preferences().SetInt(prefPrivacyTags, groups)
+}
+
+func (s *Settings) privacyOnSaveRow() *widget.Check {
+	c := widget.NewCheck("", func(b bool) {
+		fyne.CurrentApp().Preferences().SetBool(prefPrivacyOnSave, b)
+	})
+	c.Checked = fyne.CurrentApp().Preferences().Bool(prefPrivacyOnSave)
+	s.privacyOnSave = c
+	s.updatePrivacyOnSave()
+	return c
+}
+
+// privacy profile is not applied on save in sidecar mode as photo files are kept unchanged
+func (s *Settings) updatePrivacyOnSave() {
+	if sidecarModePref() {
+		s.privacyOnSave.Text = "Remove selected metadata from photos on save (not applied in sidecar mode)"
+		s.privacyOnSave.Disable()
+	} else {
+		s.privacyOnSave.Text = "Remove selected metadata from photos on save"
+		s.privacyOnSave.Enable()
+	}
+	s.privacyOnSave.Refresh()
+}
@@ -0,0 +1,303 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// Save results
+type SaveReport struct {
+	Total      int
+	Done       int
+	Dropped    int
+	Updated    int
+	Described  int
+	Geotagged  int
+	Touched    int
+	Verified   int
+	Unverified int // rewritten files not verified, their backups are kept
+	Sidecars   int
+	Sanitized  int
+	Exported   int
+	Failed     []string
+	Canceled   bool
+}
+
+// Save choosed photos:
+// 1. move dropped photo to droppped folder
+// 2. update exif dates with file modify date or input date
+// 3. write positions matched from GPX track to EXIF GPS tags
+// 4. write edited titles, captions and keywords to XMP and IPTC
+// 5. remove metadata of privacy profile if it is applied on save
+// In sidecar mode XMP sidecar files are written instead
+func (l *PhotoList) savePhotoList() {
+	if invalid := l.invalidDates(); len(invalid) > 0 {
+		dialog.ShowError(fmt.Errorf("fix invalid entered dates before saving:\n%s", strings.Join(invalid, "\n")), wMain)
+		return
+	}
+	dialog.ShowConfirm("Ready to save changes", "Proceed?",
+		func(b bool) {
+			switch {
+			case b && sidecarModePref():
+				l.runInBackground("Saving sidecars", l.saveSidecars)
+			case b:
+				l.runInBackground("Saving changes", l.save)
+			}
+		},
+		wMain)
+}
+
+// names of files with invalid entered dates
+func (l *PhotoList) invalidDates() []string {
+	files := []string{}
+	for _, p := range l.List {
+		if !p.Droped && p.DateChoice == ChoiceEnteredDate && (p.DateInvalid || p.Dates[ChoiceEnteredDate].IsZero()) {
+			files = append(files, filepath.Base(p.File))
+		}
+	}
+	return files
+}
+
+// Set modification times of not dropped photos to their EXIF dates without rewriting files
+func (l *PhotoList) fixFileTimes() {
+	dialog.ShowConfirm("Set file times to EXIF dates", "Proceed?",
+		func(b bool) {
+			if b {
+				l.runInBackground("Setting file times", l.touchFiles)
+			}
+		},
+		wMain)
+}
+
+// run work in background showing progress dialog with cancel button
+func (l *PhotoList) runInBackground(title string, work func(next func(i int, p *Photo) bool) *SaveReport) {
+	var canceled atomic.Bool
+
+	fileLabel := widget.NewLabel("")
+	progress := widget.NewProgressBar()
+	progress.Max = float64(len(l.List))
+	dlg := dialog.NewCustom(title, "Cancel", container.NewVBox(fileLabel, progress), wMain)
+	dlg.SetOnClosed(func() { canceled.Store(true) })
+	dlg.Resize(fyne.NewSize(480, 0))
+	dlg.Show()
+
+	go func() {
+		r := work(func(i int, p *Photo) bool {
+			fileLabel.SetText(filepath.Base(p.File))
+			progress.SetValue(float64(i))
+			return !canceled.Load()
+		})
+		dlg.Hide()
+		l.showSaveReport(r)
+	}()
+}
+
+// save photos one by one calling next before each file, stop when next returns false
+func (l *PhotoList) save(next func(i int, p *Photo) bool) *SaveReport {
+	r := &SaveReport{Total: len(l.List)}
+	dropDirOk := false
+	dropDirName := filepath.Join(l.Folder, "dropped")
+	backupDirOk := false
+	backupDirName := filepath.Join(l.Folder, "original")
+	dateTags := dateTagsPref()
+	setFileTime := fyne.CurrentApp().Preferences().Bool(prefSetFileTime)
+	removeBackups := fyne.CurrentApp().Preferences().Bool(prefRemoveBackups)
+	privacy := 0
+	if fyne.CurrentApp().Preferences().Bool(prefPrivacyOnSave) {
+		privacy = privacyTagsPref()
+	}
+	for i, p := range l.List {
+		if !next(i, p) {
+			r.Canceled = true
+			break
+		}
+		r.Done++
+		if p.Droped {
+			// move file to drop dir
+			if !dropDirOk {
+				err := os.Mkdir(dropDirName, 0775)
+				if err != nil && !errors.Is(err, fs.ErrExist) {
+					r.fail(p, err)
+					continue
+				}
+				dropDirOk = true
+			}
+			sidecar, hasSidecar := p.sidecarFile() // sidecar is not left without its photo
+			err := os.Rename(p.File, filepath.Join(dropDirName, filepath.Base(p.File)))
+			if err != nil {
+				r.fail(p, err)
+				continue
+			}
+			r.Dropped++
+			if hasSidecar {
+				if err := os.Rename(sidecar, filepath.Join(dropDirName, filepath.Base(sidecar))); err != nil {
+					r.fail(p, err)
+				}
+			}
+			continue
+		}
+		rewritten := false
+		// changes are counted when the rewritten file is verified
+		var updated, geotagged, described, sanitized bool
+		failed := func(err error) {
+			r.fail(p, err)
+			if rewritten {
+				r.Unverified++
+			}
+		}
+		if p.DateChoice != ChoiceExifDate || p.gpsChanged || p.descriptionChanged {
+			// backup original file and make file copy with modified metadata
+			if !backupDirOk {
+				err := os.Mkdir(backupDirName, 0775)
+				if err != nil && !errors.Is(err, fs.ErrExist) {
+					r.fail(p, err)
+					continue
+				}
+				backupDirOk = true
+			}
+		}
+		tags := 0
+		if p.DateChoice != ChoiceExifDate {
+			err := updateExifDate(p.File, backupDirName, p.Dates[p.DateChoice], dateTags)
+			if err != nil {
+				failed(err)
+				continue
+			}
+			updated = true
+			tags = dateTags
+			rewritten = true
+		}
+		if p.gpsChanged && p.GPS != nil {
+			err := updateExifGPS(p.File, backupDirName, rewritten, *p.GPS)
+			if err != nil {
+				failed(err)
+				continue
+			}
+			geotagged = true
+			rewritten = true
+		}
+		if p.descriptionChanged {
+			err := updateDescription(p.File, backupDirName, rewritten, p)
+			if err != nil {
+				failed(err)
+				continue
+			}
+			described = true
+			rewritten = true
+		}
+		if privacy != 0 {
+			ok, err := sanitizePhoto(p.File, backupDirName, rewritten, privacy)
+			if err != nil {
+				failed(err)
+				continue
+			}
+			if ok {
+				sanitized = true
+				backupDirOk = true
+				rewritten = true
+			}
+		}
+		if rewritten {
+			bak := filepath.Join(backupDirName, filepath.Base(p.File))
+			err := verifyRewrite(p.File, bak, p.Dates[p.DateChoice], tags)
+			if err != nil {
+				r.fail(p, fmt.Errorf("not verified, backup kept: %w", err))
+				r.Unverified++
+			} else {
+				r.Verified++
+				r.Updated += count(updated)
+				r.Geotagged += count(geotagged)
+				r.Described += count(described)
+				r.Sanitized += count(sanitized)
+				if removeBackups {
+					if err := os.Remove(bak); err != nil {
+						r.fail(p, fmt.Errorf("backup not removed: %w", err))
+					}
+				}
+			}
+		}
+		if setFileTime && !p.Dates[p.DateChoice].IsZero() {
+			r.touch(p, p.Dates[p.DateChoice])
+		}
+	}
+	if backupDirOk && removeBackups {
+		os.Remove(backupDirName) // only if it is empty
+	}
+	return r
+}
+
+// set modification times to EXIF dates calling next before each file, stop when next returns false
+func (l *PhotoList) touchFiles(next func(i int, p *Photo) bool) *SaveReport {
+	r := &SaveReport{Total: len(l.List)}
+	for i, p := range l.List {
+		if !next(i, p) {
+			r.Canceled = true
+			break
+		}
+		r.Done++
+		if !p.Droped && !p.Dates[ChoiceExifDate].IsZero() {
+			r.touch(p, p.Dates[ChoiceExifDate])
+		}
+	}
+	return r
+}
+
+// set file access and modification times to date
+func (r *SaveReport) touch(p *Photo, d Date) {
+	err := os.Chtimes(p.File, d.Time, d.Time)
+	if err != nil {
+		r.fail(p, err)
+		return
+	}
+	r.Touched++
+}
+
+// 1 if changed, 0 otherwise
+func count(changed bool) int {
+	if changed {
+		return 1
+	}
+	return 0
+}
+
+func (r *SaveReport) fail(p *Photo, err error) {
+	r.Failed = append(r.Failed, fmt.Sprintf("%s: %v", filepath.Base(p.File), err))
+}
+
+// show summary and reload photo list when it is closed
+func (l *PhotoList) showSaveReport(r *SaveReport) {
+	title := "Completed"
+	if r.Canceled {
+		title = "Canceled"
+	}
+	summary := widget.NewLabel(fmt.Sprintf("Processed %d of %d files\nDropped: %d\nDates updated: %d\nDescriptions updated: %d\nPositions written: %d\nMetadata removed: %d\nVerified: %d\nNot verified: %d\nFile times set: %d\nSidecars written: %d\nFailed: %d",
+		r.Done, r.Total, r.Dropped, r.Updated, r.Described, r.Geotagged, r.Sanitized, r.Verified, r.Unverified, r.Touched, r.Sidecars, len(r.Failed)))
+	if r.Exported > 0 {
+		summary.SetText(summary.Text + fmt.Sprintf("\nExported: %d", r.Exported))
+	}
+	content := container.NewVBox(summary)
+	if len(r.Failed) > 0 {
+		errs := widget.NewLabel(strings.Join(r.Failed, "\n"))
+		scroll := container.NewVScroll(errs)
+		scroll.SetMinSize(fyne.NewSize(480, 160))
+		content.Add(scroll)
+	}
+	dlg := dialog.NewCustom(title, "Ok", content, wMain)
+	dlg.SetOnClosed(func() {
+		if r.Dropped+r.Updated+r.Described+r.Geotagged+r.Sanitized+r.Unverified+r.Touched+r.Sidecars > 0 {
+			pl = newPhotoList(l.Folder)
+			MainLayout(pl)
+		}
+	})
+	dlg.Show()
+}
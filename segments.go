@@ -0,0 +1,127 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"os"
+)
+
+// JPEG markers
+const (
+	markerSOI   = 0xd8
+	markerEOI   = 0xd9
+	markerSOS   = 0xda
+	markerAPP1  = 0xe1
+	markerAPP13 = 0xed
+)
+
+var errNotJpeg = errors.New("not a JPEG file")
+
+// split JPEG data into header segments (SOI first, each with marker and length) and the rest starting at SOS
+func splitJpeg(data []byte) (segs [][]byte, rest []byte, err error) {
+	if len(data) < 4 || data[0] != 0xff || data[1] != markerSOI {
+		return nil, nil, errNotJpeg
+	}
+	segs = append(segs, data[:2])
+	pos := 2
+	for pos+4 <= len(data) {
+		if data[pos] != 0xff {
+			return nil, nil, errNotJpeg
+		}
+		marker := data[pos+1]
+		if marker == 0xff { // fill byte
+			pos++
+			continue
+		}
+		if marker == markerSOS || marker == markerEOI {
+			return segs, data[pos:], nil
+		}
+		end := pos + 2 + int(data[pos+2])<<8 + int(data[pos+3])
+		if end > len(data) {
+			return nil, nil, errNotJpeg
+		}
+		segs = append(segs, data[pos:end])
+		pos = end
+	}
+	return nil, nil, errNotJpeg
+}
+
+// join segments and the rest back into JPEG data
+func joinJpeg(segs [][]byte, rest []byte) []byte {
+	var b bytes.Buffer
+	for _, s := range segs {
+		b.Write(s)
+	}
+	b.Write(rest)
+	return b.Bytes()
+}
+
+// make APP segment with marker and payload
+func newSegment(marker byte, payload []byte) []byte {
+	n := len(payload) + 2
+	return append([]byte{0xff, marker, byte(n >> 8), byte(n)}, payload...)
+}
+
+// find index of the first APP segment with marker and payload prefix, -1 if none
+func findSegment(segs [][]byte, marker byte, prefix []byte) int {
+	for i, s := range segs {
+		if len(s) >= 4 && s[1] == marker && bytes.HasPrefix(s[4:], prefix) {
+			return i
+		}
+	}
+	return -1
+}
+
+// read header segments of JPEG file up to the start of scan
+func readJpegSegments(file string) ([][]byte, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	r := bufio.NewReader(f)
+	soi := make([]byte, 2)
+	if _, err := io.ReadFull(r, soi); err != nil || soi[0] != 0xff || soi[1] != markerSOI {
+		return nil, errNotJpeg
+	}
+	segs := [][]byte{soi}
+	for {
+		head := make([]byte, 4)
+		if _, err := io.ReadFull(r, head[:2]); err != nil {
+			return nil, errNotJpeg
+		}
+		if head[0] != 0xff {
+			return nil, errNotJpeg
+		}
+		if head[1] == 0xff { // fill byte
+			r.UnreadByte()
+			continue
+		}
+		if head[1] == markerSOS || head[1] == markerEOI {
+			return segs, nil
+		}
+		if _, err := io.ReadFull(r, head[2:]); err != nil {
+			return nil, errNotJpeg
+		}
+		n := int(head[2])<<8 + int(head[3])
+		if n < 2 {
+			return nil, errNotJpeg
+		}
+		seg := append(head, make([]byte, n-2)...)
+		if _, err := io.ReadFull(r, seg[4:]); err != nil {
+			return nil, errNotJpeg
+		}
+		segs = append(segs, seg)
+	}
+}
+
+// insert APP segment after SOI and APP0/APP1 segments
+func insertSegment(segs [][]byte, seg []byte) [][]byte {
+	at := 1
+	for at < len(segs) && (segs[at][1] == 0xe0 || segs[at][1] == markerAPP1) {
+		at++
+	}
+	return append(segs[:at], append([][]byte{seg}, segs[at:]...)...)
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/widget"
+)
+
+// preference keys
+const (
+	prefDateTags      = "dateTags"
+	prefNamePatterns  = "namePatterns"
+	prefSetFileTime   = "setFileTime"
+	prefRemoveBackups = "removeBackups"
+)
+
+var dateTagItems = []struct {
+	tag  int
+	name string
+}{
+	{WriteDateTimeOriginal, "DateTimeOriginal"},
+	{WriteDateTimeDigitized, "DateTimeDigitized"},
+	{WriteDateTime, "DateTime"},
+	{WriteXMPDateCreated, "XMP DateCreated"},
+}
+
+// date tags to write on save
+func dateTagsPref() int {
+	return fyne.CurrentApp().Preferences().IntWithFallback(prefDateTags, DefaultDateTags)
+}
+
+func (s *Settings) dateTagsRow() *widget.CheckGroup {
+	tags := dateTagsPref()
+	names := []string{}
+	selected := []string{}
+	for _, item := range dateTagItems {
+		names = append(names, item.name)
+		if tags&item.tag != 0 {
+			selected = append(selected, item.name)
+		}
+	}
+	group := widget.NewCheckGroup(names, nil)
+	group.SetSelected(selected)
+	group.OnChanged = s.chooseDateTags
+	return group
+}
+
+func (s *Settings) chooseDateTags(selected []string) {
+	tags := 0
+	for _, item := range dateTagItems {
+		for _, name := range selected {
+			if name == item.name {
+				tags |= item.tag
+			}
+		}
+	}
+	fyne.CurrentApp().Preferences().SetInt(prefDateTags, tags)
+}
+
+func (s *Settings) namePatternsRow() *widget.Entry {
+	e := widget.NewMultiLineEntry()
+	e.SetPlaceHolder("One pattern per line, e.g. IMG-YYYYMMDD-WA\nTokens: YYYY MM DD hh mm ss")
+	e.SetText(fyne.CurrentApp().Preferences().String(prefNamePatterns))
+	e.Wrapping = fyne.TextWrapOff
+	e.SetMinRowsVisible(4)
+	e.OnChanged = func(text string) {
+		fyne.CurrentApp().Preferences().SetString(prefNamePatterns, text)
+	}
+	return e
+}
+
+func (s *Settings) setFileTimeRow() *widget.Check {
+	c := widget.NewCheck("Set file modification time to photo date on save", func(b bool) {
+		fyne.CurrentApp().Preferences().SetBool(prefSetFileTime, b)
+	})
+	c.Checked = fyne.CurrentApp().Preferences().Bool(prefSetFileTime)
+	return c
+}
+
+func (s *Settings) removeBackupsRow() *widget.Check {
+	c := widget.NewCheck("Remove backup in \"original\" folder after successful verification", func(b bool) {
+		fyne.CurrentApp().Preferences().SetBool(prefRemoveBackups, b)
+	})
+	c.Checked = fyne.CurrentApp().Preferences().Bool(prefRemoveBackups)
+	return c
+}
@@ -7,6 +7,7 @@ import (
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/app"
+	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/widget"
 )
@@ -15,6 +16,8 @@ import (
 type Settings struct {
 	fyneSettings app.SettingsSchema
 	colors       []fyne.CanvasObject
+
+	privacyOnSave *widget.Check
 }
 
 func (s *Settings) load() {
@@ -66,8 +69,30 @@ func settingsScreen() {
 		widget.NewFormItem("Scale", s.scalesRow()),
 		widget.NewFormItem("Main Color", s.colorsRow()),
 		widget.NewFormItem("Theme", s.themesRow()),
+		widget.NewFormItem("Shooting info", s.shootingInfoRow()),
+		widget.NewFormItem("List", s.listThumbnailsRow()),
+	)
+	dates := widget.NewForm(
+		widget.NewFormItem("Write tags", s.dateTagsRow()),
+		widget.NewFormItem("Filename patterns", s.namePatternsRow()),
+		widget.NewFormItem("File time", s.setFileTimeRow()),
+		widget.NewFormItem("Backups", s.removeBackupsRow()),
+		widget.NewFormItem("Sidecars", s.sidecarModeRow()),
+	)
+	maps := widget.NewForm(
+		widget.NewFormItem("Tile folder", s.mapTilesRow()),
+		widget.NewFormItem("Outlines", s.mapOutlinesRow()),
+	)
+	tabs := container.NewAppTabs(
+		container.NewTabItem("Appearance", appearance),
+		container.NewTabItem("Dates", dates),
+		container.NewTabItem("Map", maps),
+		container.NewTabItem("Privacy", widget.NewForm(
+			widget.NewFormItem("Remove", s.privacyTagsRow()),
+			widget.NewFormItem("", s.privacyOnSaveRow()),
+		)),
 	)
-	dialog.ShowCustom("Appearance", "Ok", appearance, wMain)
+	dialog.ShowCustom("Settings", "Ok", tabs, wMain)
 }
 
 // a new settings instance with the current configuration loaded
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+)
+
+// Shift scopes
+const (
+	ScopeAll       = "All photos"
+	ScopeSelection = "Selection"
+	ScopeCamera    = "Camera"
+)
+
+// Photo date change
+type DateChange struct {
+	Photo *Photo
+	Date  Date
+}
+
+// apply date changes as entered dates
+func (l *PhotoList) applyDateChanges(changes []DateChange) {
+	for _, c := range changes {
+		c.Photo.Dates[ChoiceEnteredDate] = c.Date
+		c.Photo.DateChoice = ChoiceEnteredDate
+		c.Photo.DateInvalid = false
+	}
+	l.refresh()
+}
+
+// table previewing photo dates before and after change
+func newDateChangesPreview(changes *[]DateChange) *widget.Table {
+	titles := []string{"File Name", "Before", "After"}
+	table := widget.NewTable(
+		func() (int, int) {
+			return len(*changes) + 1, len(titles)
+		},
+		func() fyne.CanvasObject {
+			return widget.NewLabel(DateTemplate)
+		},
+		func(i widget.TableCellID, o fyne.CanvasObject) {
+			label := o.(*widget.Label)
+			if i.Row == 0 {
+				label.TextStyle.Bold = true
+				label.SetText(titles[i.Col])
+				return
+			}
+			label.TextStyle.Bold = false
+			c := (*changes)[i.Row-1]
+			switch i.Col {
+			case 0:
+				label.SetText(filepath.Base(c.Photo.File))
+			case 1:
+				label.SetText(c.Photo.Dates[c.Photo.DateChoice].String())
+			case 2:
+				label.SetText(c.Date.String())
+			}
+		})
+	return table
+}
+
+// distinct camera identities of the photos
+func (l *PhotoList) cameras() []string {
+	names := []string{}
+	seen := map[string]bool{}
+	for _, p := range l.List {
+		if id := p.CameraID(); id != "" && !seen[id] {
+			seen[id] = true
+			names = append(names, id)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// show bulk date shift dialog
+func (l *PhotoList) shiftDates() {
+	changes := []DateChange{}
+	preview := newDateChangesPreview(&changes)
+
+	scope := widget.NewRadioGroup([]string{ScopeAll, ScopeSelection, ScopeCamera}, nil)
+	scope.Horizontal = true
+	camera := widget.NewSelect(l.cameras(), nil)
+	sign := widget.NewSelect([]string{"+", "-"}, nil)
+	sign.SetSelected("+")
+	days, hours, minutes, seconds := newNumberEntry(), newNumberEntry(), newNumberEntry(), newNumberEntry()
+	apply := widget.NewButton("Apply", nil)
+	apply.Importance = widget.HighImportance
+
+	update := func() {
+		offset := time.Duration(entryNumber(days))*24*time.Hour +
+			time.Duration(entryNumber(hours))*time.Hour +
+			time.Duration(entryNumber(minutes))*time.Minute +
+			time.Duration(entryNumber(seconds))*time.Second
+		if sign.Selected == "-" {
+			offset = -offset
+		}
+		photos := l.List
+		switch scope.Selected {
+		case ScopeSelection:
+			photos = l.scopeSelection()
+		case ScopeCamera:
+			photos = nil
+			if camera.Selected != "" {
+				photos = l.cameraPhotos(camera.Selected)
+			}
+		}
+		changes = changes[:0]
+		for _, p := range photos {
+			d := p.Dates[p.DateChoice]
+			if p.Droped || d.IsZero() {
+				continue
+			}
+			changes = append(changes, DateChange{Photo: p, Date: d.Add(offset)})
+		}
+		preview.Refresh()
+		apply.Enable()
+		for _, e := range []*widget.Entry{days, hours, minutes, seconds} {
+			if e.Validate() != nil {
+				apply.Disable() // shift is not applied with invalid numbers taken as 0
+			}
+		}
+	}
+	scope.OnChanged = func(s string) {
+		if s == ScopeCamera {
+			camera.Enable()
+		} else {
+			camera.Disable()
+		}
+		update()
+	}
+	camera.OnChanged = func(string) { update() }
+	sign.OnChanged = func(string) { update() }
+	for _, e := range []*widget.Entry{days, hours, minutes, seconds} {
+		e.OnChanged = func(string) { update() }
+	}
+	scope.SetSelected(ScopeAll)
+
+	form := widget.NewForm(
+		widget.NewFormItem("Photos", scope),
+		widget.NewFormItem("Camera", camera),
+		widget.NewFormItem("Shift", container.NewBorder(nil, nil, sign, nil, container.NewGridWithColumns(8,
+			days, widget.NewLabel("days"),
+			hours, widget.NewLabel("hours"),
+			minutes, widget.NewLabel("min"),
+			seconds, widget.NewLabel("sec")))),
+	)
+	content := container.NewBorder(form, container.NewHBox(layout.NewSpacer(), apply), nil, nil, preview)
+	dlg := dialog.NewCustom("Shift dates", "Cancel", content, wMain)
+	apply.OnTapped = func() {
+		dlg.Hide()
+		l.applyDateChanges(changes)
+	}
+	dlg.Resize(fyne.NewSize(800, 600))
+	dlg.Show()
+}
+
+// photos taken by camera
+func (l *PhotoList) cameraPhotos(camera string) []*Photo {
+	photos := []*Photo{}
+	for _, p := range l.List {
+		if p.CameraID() == camera {
+			photos = append(photos, p)
+		}
+	}
+	return photos
+}
+
+// entry accepting non negative integer
+func newNumberEntry() *widget.Entry {
+	e := widget.NewEntry()
+	e.SetPlaceHolder("0")
+	e.Validator = func(s string) error {
+		if strings.TrimSpace(s) == "" {
+			return nil
+		}
+		_, err := strconv.ParseUint(strings.TrimSpace(s), 10, 32)
+		return err
+	}
+	return e
+}
+
+// number in entry, 0 if invalid
+func entryNumber(e *widget.Entry) int {
+	n, _ := strconv.Atoi(strings.TrimSpace(e.Text))
+	if n < 0 {
+		return 0
+	}
+	return n
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/widget"
+	"github.com/tajtiattila/metadata/exif"
+)
+
+const prefShootingInfo = "shootingInfo"
+
+// Shooting info fields shown in frame column caption
+const (
+	InfoExposure = 1 << iota
+	InfoAperture
+	InfoISO
+	InfoFocalLength
+	InfoExposureBias
+)
+
+const DefaultShootingInfo = InfoExposure | InfoAperture | InfoISO
+
+var shootingInfoItems = []struct {
+	field int
+	name  string
+}{
+	{InfoExposure, "Shutter speed"},
+	{InfoAperture, "Aperture"},
+	{InfoISO, "ISO"},
+	{InfoFocalLength, "Focal length"},
+	{InfoExposureBias, "Exposure compensation"},
+}
+
+// Formatted exposure parameters from EXIF
+type ShootingInfo struct {
+	Exposure     string
+	Aperture     string
+	ISO          string
+	FocalLength  string
+	ExposureBias string
+}
+
+// get exposure parameters
+func getShootingInfo(x *exif.Exif) ShootingInfo {
+	if x == nil {
+		return ShootingInfo{}
+	}
+	return ShootingInfo{
+		Exposure:     formatExposureTime(x),
+		Aperture:     formatAperture(x),
+		ISO:          formatISO(x),
+		FocalLength:  formatFocalLength(x),
+		ExposureBias: formatExposureBias(x),
+	}
+}
+
+// caption with fields selected by mask
+func (s ShootingInfo) caption(fields int) string {
+	values := []string{}
+	for _, f := range []struct {
+		field int
+		value string
+	}{
+		{InfoExposure, s.Exposure},
+		{InfoAperture, s.Aperture},
+		{InfoISO, s.ISO},
+		{InfoFocalLength, s.FocalLength},
+		{InfoExposureBias, s.ExposureBias},
+	} {
+		if fields&f.field != 0 && f.value != "" {
+			values = append(values, f.value)
+		}
+	}
+	return strings.Join(values, "  ")
+}
+
+// shooting info fields to show in frame columns
+func shootingInfoPref() int {
+	return fyne.CurrentApp().Preferences().IntWithFallback(prefShootingInfo, DefaultShootingInfo)
+}
+
+func (s *Settings) shootingInfoRow() *widget.CheckGroup {
+	fields := shootingInfoPref()
+	names := []string{}
+	selected := []string{}
+	for _, item := range shootingInfoItems {
+		names = append(names, item.name)
+		if fields&item.field != 0 {
+			selected = append(selected, item.name)
+		}
+	}
+	group := widget.NewCheckGroup(names, nil)
+	group.SetSelected(selected)
+	group.OnChanged = s.chooseShootingInfo
+	return group
+}
+
+func (s *Settings) chooseShootingInfo(selected []string) {
+	fields := 0
+	for _, item := range shootingInfoItems {
+		for _, name := range selected {
+			if name == item.name {
+				fields |= item.field
+			}
+		}
+	}
+	fyne.CurrentApp().Preferences().SetInt(prefShootingInfo, fields)
+	if pl != nil {
+		pl.refresh()
+	}
+}
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"encoding/xml"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+)
+
+const prefSidecarMode = "sidecarMode"
+
+// XMP namespaces of sidecar properties
+const (
+	nsXMP  = "http://ns.adobe.com/xap/1.0/"
+	nsEXIF = "http://ns.adobe.com/exif/1.0/"
+)
+
+var (
+	xmpRating           = XMPName{"xmp", nsXMP, "Rating"}
+	xmpLabel            = XMPName{"xmp", nsXMP, "Label"}
+	xmpCreateDate       = XMPName{"xmp", nsXMP, "CreateDate"}
+	xmpDateTimeOriginal = XMPName{"exif", nsEXIF, "DateTimeOriginal"}
+	xmpGPSLatitude      = XMPName{"exif", nsEXIF, "GPSLatitude"}
+	xmpGPSLongitude     = XMPName{"exif", nsEXIF, "GPSLongitude"}
+)
+
+// Rating of rejected photo
+const RatingRejected = -1
+
+// color labels used by Lightroom and darktable
+var labelOptions = []string{"", "Red", "Yellow", "Green", "Blue", "Purple"}
+
+var ratingOptions = []string{"", "★", "★★", "★★★", "★★★★", "★★★★★"}
+
+// write changes to sidecar files instead of photo files
+func sidecarModePref() bool {
+	return fyne.CurrentApp().Preferences().Bool(prefSidecarMode)
+}
+
+// existing sidecar file of photo, either "name.xmp" (Lightroom) or "name.jpg.xmp" (darktable),
+// new sidecar name and false if there is none, "name.xmp" is not used if another file has the same name
+// as RAW file of RAW+JPEG pair
+func (p *Photo) sidecarFile() (string, bool) {
+	candidates := []string{
+		strings.TrimSuffix(p.File, filepath.Ext(p.File)) + ".xmp",
+		p.File + ".xmp",
+	}
+	if p.sharedBase {
+		candidates = candidates[1:]
+	}
+	for _, c := range candidates {
+		if _, err := os.Stat(c); err == nil {
+			return c, true
+		}
+	}
+	return candidates[0], false
+}
+
+// number of folder files by name without extension in lower case, sidecars are not counted
+func baseNameCounts(files []fs.DirEntry) map[string]int {
+	counts := map[string]int{}
+	for _, f := range files {
+		ext := filepath.Ext(f.Name())
+		if f.IsDir() || strings.EqualFold(ext, ".xmp") {
+			continue
+		}
+		counts[strings.ToLower(strings.TrimSuffix(f.Name(), ext))]++
+	}
+	return counts
+}
+
+// read rating, label, drop status and date from existing sidecar in sidecar mode,
+// date with wall clock time differing from EXIF date becomes entered date
+func (p *Photo) readSidecar() {
+	name, ok := p.sidecarFile()
+	if !ok {
+		return
+	}
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return
+	}
+	values, _ := xmpProperties(data)
+	value := func(n XMPName) string {
+		if v := values[n.xmlName()]; len(v) > 0 {
+			return v[0]
+		}
+		return ""
+	}
+	if r, err := strconv.Atoi(value(xmpRating)); err == nil {
+		if r == RatingRejected {
+			p.Droped = true
+		} else if r > 0 && r < len(ratingOptions) {
+			p.Rating = r
+		}
+	}
+	p.Label = value(xmpLabel)
+	p.setXMPDescription(values)
+	lat, ok1 := parseXMPGPSCoordinate(value(xmpGPSLatitude))
+	long, ok2 := parseXMPGPSCoordinate(value(xmpGPSLongitude))
+	if ok1 && ok2 {
+		p.GPS = &GeoPoint{Lat: lat, Long: long}
+	}
+	for _, n := range []XMPName{xmpDateTimeOriginal, xmpDateCreated, xmpCreateDate} {
+		d, err := parseXMPDate(value(n))
+		if err != nil {
+			continue
+		}
+		if d.Format(DateFormat) != p.Dates[ChoiceExifDate].Format(DateFormat) {
+			p.Dates[ChoiceEnteredDate] = d
+			p.DateChoice = ChoiceEnteredDate
+		}
+		break
+	}
+}
+
+func (n XMPName) xmlName() xml.Name {
+	return xml.Name{Space: n.URI, Local: n.Local}
+}
+
+// write photo rating, label, drop status, description, position and chosen date to sidecar, create sidecar if there is none
+func (p *Photo) writeSidecar(tags int) error {
+	name, ok := p.sidecarFile()
+	packet := xmpEmptyPacket
+	if ok {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			return err
+		}
+		packet = string(data)
+	}
+	switch {
+	case p.Droped:
+		packet = xmpSet(packet, xmpRating, strconv.Itoa(RatingRejected))
+	case p.Rating > 0:
+		packet = xmpSet(packet, xmpRating, strconv.Itoa(p.Rating))
+	default:
+		packet = xmpRemove(packet, xmpRating)
+	}
+	if p.Label != "" {
+		packet = xmpSet(packet, xmpLabel, p.Label)
+	} else {
+		packet = xmpRemove(packet, xmpLabel)
+	}
+	packet = p.editXMPDescription(packet)
+	if p.gpsChanged && p.GPS != nil {
+		packet = xmpSet(packet, xmpGPSLatitude, xmpGPSCoordinate(p.GPS.Lat, "N", "S"))
+		packet = xmpSet(packet, xmpGPSLongitude, xmpGPSCoordinate(p.GPS.Long, "E", "W"))
+	}
+	if p.DateChoice != ChoiceExifDate {
+		d := p.Dates[p.DateChoice].XMPString()
+		packet = xmpSet(packet, xmpDateTimeOriginal, d)
+		if tags&WriteXMPDateCreated != 0 {
+			packet = xmpSet(packet, xmpDateCreated, d)
+		}
+	}
+	return os.WriteFile(name, []byte(packet), 0664)
+}
+
+// photo has changes worth a sidecar or sidecar exists already
+func (p *Photo) needsSidecar() bool {
+	if _, ok := p.sidecarFile(); ok {
+		return true
+	}
+	return p.Droped || p.Rating > 0 || p.Label != "" || p.DateChoice != ChoiceExifDate || p.descriptionChanged || p.gpsChanged
+}
+
+// save sidecars calling next before each file, stop when next returns false
+func (l *PhotoList) saveSidecars(next func(i int, p *Photo) bool) *SaveReport {
+	r := &SaveReport{Total: len(l.List)}
+	tags := dateTagsPref()
+	for i, p := range l.List {
+		if !next(i, p) {
+			r.Canceled = true
+			break
+		}
+		r.Done++
+		if !p.needsSidecar() {
+			continue
+		}
+		if err := p.writeSidecar(tags); err != nil {
+			r.fail(p, err)
+			continue
+		}
+		r.Sidecars++
+		if p.Droped {
+			r.Dropped++
+		}
+	}
+	return r
+}
+
+// rating and color label choice shown in sidecar mode
+func (p *Photo) ratingInput() *fyne.Container {
+	rating := widget.NewSelect(ratingOptions, func(s string) {
+		for i, o := range ratingOptions {
+			if o == s {
+				p.Rating = i
+			}
+		}
+	})
+	rating.PlaceHolder = "Rating"
+	if p.Rating > 0 {
+		rating.SetSelected(ratingOptions[p.Rating])
+	}
+	label := widget.NewSelect(labelOptions, func(s string) { p.Label = s })
+	label.PlaceHolder = "Label"
+	label.SetSelected(p.Label)
+	return container.NewGridWithColumns(2, rating, label)
+}
+
+func (s *Settings) sidecarModeRow() *widget.Check {
+	c := widget.NewCheck("Write changes to XMP sidecar files, keep photo files unchanged", func(b bool) {
+		fyne.CurrentApp().Preferences().SetBool(prefSidecarMode, b)
+		if s.privacyOnSave != nil {
+			s.updatePrivacyOnSave()
+		}
+		if pl != nil {
+			pl.refresh()
+		}
+	})
+	c.Checked = sidecarModePref()
+	return c
+}
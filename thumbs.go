@@ -0,0 +1,227 @@
+package main
+
+import (
+	"bytes"
+	"image"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/disintegration/imaging"
+	"github.com/tajtiattila/metadata/exif"
+	"github.com/tajtiattila/metadata/exif/exiftag"
+)
+
+const prefListThumbnails = "listThumbnails"
+
+const (
+	thumbSize        = 48  // List tab thumbnail column size
+	previewSize      = 320 // List tab preview pane size
+	maxCachedPreview = 32  // preview images kept in cache
+
+	thumbRefreshDelay = 200 * time.Millisecond // thumbnails loaded meanwhile are shown by one table refresh
+)
+
+// cache of downscaled photo images loaded in background
+type thumbCache struct {
+	size    int
+	max     int // max cached images, 0 is unlimited
+	mu      sync.Mutex
+	images  map[string]image.Image
+	order   []string // cached files, oldest first
+	loading map[string]bool
+}
+
+func newThumbCache(size, max int) *thumbCache {
+	return &thumbCache{
+		size:    size,
+		max:     max,
+		images:  map[string]image.Image{},
+		loading: map[string]bool{},
+	}
+}
+
+// cached image of file, if it is not cached yet nil is returned and loaded is called when it is ready
+func (c *thumbCache) get(file string, loaded func()) image.Image {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if m, ok := c.images[file]; ok {
+		return m
+	}
+	if !c.loading[file] {
+		c.loading[file] = true
+		go c.load(file, loaded)
+	}
+	return nil
+}
+
+func (c *thumbCache) load(file string, loaded func()) {
+	m := loadThumbnail(file, c.size)
+	c.mu.Lock()
+	delete(c.loading, file)
+	if m != nil {
+		c.images[file] = m
+		c.order = append(c.order, file)
+		if c.max > 0 && len(c.order) > c.max {
+			delete(c.images, c.order[0])
+			c.order = c.order[1:]
+		}
+	}
+	c.mu.Unlock()
+	if m != nil && loaded != nil {
+		loaded()
+	}
+}
+
+// downscaled photo image fitting size x size, embedded EXIF thumbnail is used if it is big enough
+func loadThumbnail(file string, size int) image.Image {
+	if x, err := getJpegExif(file); err == nil && x != nil && len(x.Thumb) > 0 {
+		if m, err := imaging.Decode(bytes.NewReader(x.Thumb)); err == nil {
+			b := m.Bounds()
+			if b.Dx() >= size || b.Dy() >= size {
+				return imaging.Fit(orientImage(m, exifOrientation(x)), size, size, imaging.Box)
+			}
+		}
+	}
+	m, err := imaging.Open(file, imaging.AutoOrientation(true))
+	if err != nil {
+		return nil
+	}
+	return imaging.Fit(m, size, size, imaging.Box)
+}
+
+func exifOrientation(x *exif.Exif) int {
+	if v := x.Tag(exiftag.Orientation).Short(); len(v) > 0 {
+		return int(v[0])
+	}
+	return 1
+}
+
+// apply EXIF orientation to image
+func orientImage(m image.Image, orientation int) image.Image {
+	switch orientation {
+	case 2:
+		return imaging.FlipH(m)
+	case 3:
+		return imaging.Rotate180(m)
+	case 4:
+		return imaging.FlipV(m)
+	case 5:
+		return imaging.Transpose(m)
+	case 6:
+		return imaging.Rotate270(m)
+	case 7:
+		return imaging.Transverse(m)
+	case 8:
+		return imaging.Rotate90(m)
+	}
+	return m
+}
+
+// refresh List tab table once for thumbnails loaded within refresh delay
+func (l *PhotoList) thumbLoaded() {
+	if !l.thumbsLoaded.CompareAndSwap(false, true) {
+		return
+	}
+	time.AfterFunc(thumbRefreshDelay, func() {
+		l.thumbsLoaded.Store(false)
+		l.table.Refresh()
+	})
+}
+
+func listThumbnailsPref() bool {
+	return fyne.CurrentApp().Preferences().Bool(prefListThumbnails)
+}
+
+// List tab preview pane
+type rowPreview struct {
+	panel *fyne.Container
+	image *canvas.Image
+	title *widget.Label
+	date  *widget.Label
+	photo *Photo
+	cache *thumbCache
+}
+
+func newRowPreview() *rowPreview {
+	r := &rowPreview{
+		image: canvas.NewImageFromImage(nil),
+		title: widget.NewLabel(""),
+		date:  widget.NewLabel(""),
+		cache: newThumbCache(previewSize, maxCachedPreview),
+	}
+	r.image.FillMode = canvas.ImageFillContain
+	r.image.SetMinSize(fyne.NewSize(previewSize, previewSize))
+	r.title.TextStyle.Bold = true
+	r.title.Wrapping = fyne.TextTruncate
+	r.panel = container.NewBorder(nil, container.NewVBox(r.title, r.date), nil, nil, r.image)
+	r.panel.Hide()
+	return r
+}
+
+// show photo in preview pane
+func (r *rowPreview) show(p *Photo) {
+	if !r.panel.Visible() || p == r.photo {
+		return
+	}
+	r.photo = p
+	r.title.SetText(filepath.Base(p.File))
+	r.date.SetText(p.Dates[p.DateChoice].String())
+	r.image.Image = r.cache.get(p.File, func() {
+		if r.photo == p {
+			r.image.Image = r.cache.get(p.File, nil)
+			r.image.Refresh()
+		}
+	})
+	r.image.Refresh()
+}
+
+// show or hide List tab preview pane
+func (l *PhotoList) togglePreview() {
+	r := l.preview
+	if r.panel.Visible() {
+		r.panel.Hide()
+		return
+	}
+	r.panel.Show()
+	if r.photo == nil && len(l.View) > 0 {
+		r.show(l.View[0])
+	}
+}
+
+// table columns shown in List tab
+func (l *PhotoList) updateListColumns() {
+	l.columns = []int{colFileName, colExifDate, colExifSource, colFileDate, colNameDate, colEnteredDate, colChosenDate, colDropped, colKeywords, colGPS}
+	if listThumbnailsPref() {
+		l.columns = append([]int{colThumbnail}, l.columns...)
+	}
+	if l.table == nil {
+		return
+	}
+	// table columns have template width unless set
+	width := l.table.CreateCell().MinSize().Width
+	if listThumbnailsPref() {
+		width = thumbSize + 2*theme.Padding()
+	}
+	l.table.SetColumnWidth(0, width)
+	l.header.SetColumnWidth(0, width)
+	l.table.Refresh()
+	l.header.Refresh()
+}
+
+func (s *Settings) listThumbnailsRow() *widget.Check {
+	c := widget.NewCheck("Thumbnail column in List tab", nil)
+	c.SetChecked(listThumbnailsPref())
+	c.OnChanged = func(on bool) {
+		fyne.CurrentApp().Preferences().SetBool(prefListThumbnails, on)
+		if pl != nil {
+			pl.updateListColumns()
+		}
+	}
+	return c
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	_ "time/tzdata"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// Zone of system clock, dates are kept without offset
+const ZoneLocal = "Local"
+
+const prefZonePrefix = "zone:"
+
+// time zone choices, any IANA zone name may be entered as well
+var zoneOptions = func() []string {
+	options := []string{ZoneLocal, "UTC"}
+	for h := -12; h <= 14; h++ {
+		if h != 0 {
+			options = append(options, fmt.Sprintf("UTC%+03d:00", h))
+		}
+	}
+	return append(options,
+		"Europe/London", "Europe/Paris", "Europe/Moscow", "America/New_York", "America/Chicago",
+		"America/Los_Angeles", "Asia/Dubai", "Asia/Kolkata", "Asia/Shanghai", "Asia/Tokyo", "Australia/Sydney")
+}()
+
+// parse zone choice, nil location for system zone
+func parseZone(spec string) (*time.Location, error) {
+	spec = strings.TrimSpace(spec)
+	switch {
+	case spec == "" || spec == ZoneLocal:
+		return nil, nil
+	case len(spec) > 3 && strings.HasPrefix(spec, "UTC") && (spec[3] == '+' || spec[3] == '-'):
+		t, err := time.Parse(OffsetFormat, spec[3:])
+		if err != nil {
+			return nil, fmt.Errorf("invalid zone offset %q, expected UTC+hh:mm", spec)
+		}
+		_, offset := t.Zone()
+		return time.FixedZone(spec, offset), nil
+	}
+	return time.LoadLocation(spec)
+}
+
+func folderZoneKey(folder string) string {
+	return prefZonePrefix + folder
+}
+
+func cameraZoneKey(folder, camera string) string {
+	return prefZonePrefix + folder + "|" + camera
+}
+
+// zone of photo camera, of the folder if camera zone is not set, nil for system zone
+func (l *PhotoList) photoZone(p *Photo) *time.Location {
+	prefs := fyne.CurrentApp().Preferences()
+	spec := ""
+	if p.CameraID() != "" {
+		spec = prefs.String(cameraZoneKey(l.Folder, p.CameraID()))
+	}
+	if spec == "" {
+		spec = prefs.String(folderZoneKey(l.Folder))
+	}
+	loc, err := parseZone(spec)
+	if err != nil {
+		return nil
+	}
+	return loc
+}
+
+// convert photo dates to photo zones:
+// wall clock of camera and file name dates is kept, file and GPS dates keep the instant
+func (l *PhotoList) applyZones() {
+	for _, p := range l.List {
+		loc := l.photoZone(p)
+		switch {
+		case p.ExifDateSource == SourceGPS:
+			p.Dates[ChoiceExifDate] = p.Dates[ChoiceExifDate].In(loc)
+		case !p.exifOffset:
+			p.Dates[ChoiceExifDate] = p.Dates[ChoiceExifDate].WallIn(loc)
+		}
+		p.Dates[ChoiceFileDate] = p.Dates[ChoiceFileDate].In(loc)
+		p.Dates[ChoiceNameDate] = p.Dates[ChoiceNameDate].WallIn(loc)
+	}
+}
+
+// new zone choice entry
+func newZoneEntry(spec string) *widget.SelectEntry {
+	e := widget.NewSelectEntry(zoneOptions)
+	e.SetText(spec)
+	e.Validator = func(s string) error {
+		_, err := parseZone(s)
+		return err
+	}
+	return e
+}
+
+// show folder and camera time zones dialog
+func (l *PhotoList) chooseZones() {
+	prefs := fyne.CurrentApp().Preferences()
+	folderZone := newZoneEntry(prefs.StringWithFallback(folderZoneKey(l.Folder), ZoneLocal))
+	items := []*widget.FormItem{widget.NewFormItem("Folder", folderZone)}
+	cameras := l.cameras()
+	cameraZones := []*widget.SelectEntry{}
+	for _, c := range cameras {
+		e := newZoneEntry(prefs.String(cameraZoneKey(l.Folder, c)))
+		e.SetPlaceHolder("Folder zone")
+		cameraZones = append(cameraZones, e)
+		items = append(items, widget.NewFormItem(c, e))
+	}
+	dlg := dialog.NewForm("Time zones", "Apply", "Cancel", items, func(ok bool) {
+		if !ok {
+			return
+		}
+		prefs.SetString(folderZoneKey(l.Folder), strings.TrimSpace(folderZone.Text))
+		for i, c := range cameras {
+			prefs.SetString(cameraZoneKey(l.Folder, c), strings.TrimSpace(cameraZones[i].Text))
+		}
+		l.applyZones()
+		l.refresh()
+	}, wMain)
+	dlg.Resize(fyne.NewSize(480, 0))
+	dlg.Show()
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"image/jpeg"
+	"os"
+
+	"github.com/tajtiattila/metadata/exif/exiftag"
+)
+
+var (
+	errScanDataChanged = errors.New("image data differs from backup")
+	errDateMismatch    = errors.New("written date does not read back")
+)
+
+// hash of JPEG scan data from the start of scan to the end of file
+func scanDataHash(data []byte) ([]byte, error) {
+	_, rest, err := splitJpeg(data)
+	if err != nil {
+		return nil, err
+	}
+	h := sha256.Sum256(rest)
+	return h[:], nil
+}
+
+// check rewritten file against its backup: image decodes, scan data is unchanged and date reads back
+func verifyRewrite(file, backup string, date Date, tags int) error {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	if _, err = jpeg.Decode(bytes.NewReader(data)); err != nil {
+		return fmt.Errorf("image decode: %w", err)
+	}
+	orig, err := os.ReadFile(backup)
+	if err != nil {
+		return err
+	}
+	h1, err := scanDataHash(data)
+	if err != nil {
+		return err
+	}
+	h2, err := scanDataHash(orig)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(h1, h2) {
+		return errScanDataChanged
+	}
+
+	var dateTag, subSecTag, offsetTag uint32
+	switch {
+	case tags&WriteDateTimeOriginal != 0:
+		dateTag, subSecTag, offsetTag = exiftag.DateTimeOriginal, exiftag.SubSecTimeOriginal, OffsetTimeOriginal
+	case tags&WriteDateTimeDigitized != 0:
+		dateTag, subSecTag, offsetTag = exiftag.DateTimeDigitized, exiftag.SubSecTimeDigitized, OffsetTimeDigitized
+	case tags&WriteDateTime != 0:
+		dateTag, subSecTag, offsetTag = exiftag.DateTime, exiftag.SubSecTime, OffsetTime
+	default:
+		return nil
+	}
+	x, err := getJpegExif(file)
+	if err != nil {
+		return err
+	}
+	d, ok := exifDate(x, dateTag, subSecTag, offsetTag)
+	if !ok || d.Format(DateFormat) != date.Format(DateFormat) ||
+		(date.SubSec && d.Nanosecond() != date.Nanosecond()) ||
+		(date.HasOffset && (!d.HasOffset || d.Format(OffsetFormat) != date.Format(OffsetFormat))) {
+		return errDateMismatch
+	}
+	return nil
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"regexp"
+	"strings"
+)
+
+// XMP namespaces
+const (
+	nsRDF       = "http://www.w3.org/1999/02/22-rdf-syntax-ns#"
+	nsPhotoshop = "http://ns.adobe.com/photoshop/1.0/"
+)
+
+// APP1 payload prefix of XMP packet in JPEG
+var xmpPrefix = []byte("http://ns.adobe.com/xap/1.0/\x00")
+
+var errXMPTooLong = errors.New("XMP packet too long for JPEG segment")
+
+const xmpEmptyPacket = `<?xpacket begin="` + "\ufeff" + `" id="W5M0MpCehiHzreSzNTczkc9d"?>
+<x:xmpmeta xmlns:x="adobe:ns:meta/">
+ <rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#">
+  <rdf:Description rdf:about=""/>
+ </rdf:RDF>
+</x:xmpmeta>
+<?xpacket end="w"?>`
+
+// XMP property name
+type XMPName struct {
+	Prefix string // preferred prefix if namespace is not declared in packet
+	URI    string
+	Local  string
+}
+
+var (
+	xmpDateCreated = XMPName{"photoshop", nsPhotoshop, "DateCreated"}
+)
+
+var (
+	reDescription = regexp.MustCompile(`<rdf:Description\b[^>]*?(/?)>`)
+	reRDF         = regexp.MustCompile(`<rdf:RDF\b[^>]*?(/?)>`)
+)
+
+// prefix declared in packet for namespace URI or the preferred one
+func (n XMPName) prefix(packet string) (prefix string, declared bool) {
+	re := regexp.MustCompile(`xmlns:([\w.-]+)\s*=\s*["']` + regexp.QuoteMeta(n.URI) + `["']`)
+	if m := re.FindStringSubmatch(packet); m != nil {
+		return m[1], true
+	}
+	return n.Prefix, false
+}
+
+// packet with the first rdf:Description and its match indexes, an empty description is added to
+// rdf:RDF if there is none and packet without rdf:RDF is replaced with an empty one
+func xmpFirstDescription(packet string) (string, []int) {
+	if loc := reDescription.FindStringSubmatchIndex(packet); loc != nil {
+		return packet, loc
+	}
+	const empty = `<rdf:Description rdf:about=""/>`
+	loc := reRDF.FindStringSubmatchIndex(packet)
+	switch {
+	case loc == nil:
+		packet = xmpEmptyPacket
+	case loc[3] > loc[2]: // self-closing rdf:RDF
+		packet = packet[:loc[2]] + ">" + empty + "</rdf:RDF>" + packet[loc[1]:]
+	default:
+		packet = packet[:loc[1]] + empty + packet[loc[1]:]
+	}
+	return packet, reDescription.FindStringSubmatchIndex(packet)
+}
+
+// remove property in attribute or element form from packet
+func xmpRemove(packet string, n XMPName) string {
+	p, declared := n.prefix(packet)
+	if !declared {
+		return packet
+	}
+	q := regexp.QuoteMeta(p + ":" + n.Local)
+	reAttr := regexp.MustCompile(`\s` + q + `\s*=\s*("[^"]*"|'[^']*')`)
+	reElem := regexp.MustCompile(`(?s)\s*<` + q + `\b[^>]*?/>|\s*<` + q + `\b[^>]*?>.*?</` + q + `>`)
+	packet = reAttr.ReplaceAllString(packet, "")
+	return reElem.ReplaceAllString(packet, "")
+}
+
+// set simple property value as attribute of the first rdf:Description
+func xmpSet(packet string, n XMPName, value string) string {
+	packet, loc := xmpFirstDescription(xmpRemove(packet, n))
+	p, declared := n.prefix(packet)
+	attr := " " + p + ":" + n.Local + `="` + xmlEscape(value) + `"`
+	if !declared {
+		attr = " xmlns:" + p + `="` + n.URI + `"` + attr
+	}
+	at := loc[2] // before "/>" or ">"
+	return packet[:at] + attr + packet[at:]
+}
+
+// set array property as element of the first rdf:Description, kind is "Bag", "Seq" or "Alt";
+// property is removed if there are no items
+func xmpSetArray(packet string, n XMPName, kind string, items []string) string {
+	packet = xmpRemove(packet, n)
+	if len(items) == 0 {
+		return packet
+	}
+	packet, loc := xmpFirstDescription(packet)
+	p, declared := n.prefix(packet)
+	li := "<rdf:li>"
+	if kind == "Alt" {
+		li = `<rdf:li xml:lang="x-default">`
+	}
+	var b strings.Builder
+	b.WriteString("<" + p + ":" + n.Local + "><rdf:" + kind + ">")
+	for _, item := range items {
+		b.WriteString(li + xmlEscape(item) + "</rdf:li>")
+	}
+	b.WriteString("</rdf:" + kind + "></" + p + ":" + n.Local + ">")
+	attr := ""
+	if !declared {
+		attr = " xmlns:" + p + `="` + n.URI + `"`
+	}
+	at := loc[2]
+	if loc[3] > loc[2] {
+		// open self-closing description
+		return packet[:at] + attr + ">" + b.String() + "</rdf:Description" + packet[loc[3]:]
+	}
+	return packet[:at] + attr + packet[at:loc[1]] + b.String() + packet[loc[1]:]
+}
+
+// escape XML special characters in text
+func xmlEscape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// apply packet edit to XMP segment of JPEG data, add new XMP segment if there is none
+func updateJpegXMP(data []byte, edit func(packet string) string) ([]byte, error) {
+	segs, rest, err := splitJpeg(data)
+	if err != nil {
+		return nil, err
+	}
+	packet := xmpEmptyPacket
+	i := findSegment(segs, markerAPP1, xmpPrefix)
+	if i >= 0 {
+		packet = string(segs[i][4+len(xmpPrefix):])
+	}
+	payload := append(append([]byte{}, xmpPrefix...), edit(packet)...)
+	if len(payload)+2 > 0xffff {
+		return nil, errXMPTooLong
+	}
+	seg := newSegment(markerAPP1, payload)
+	if i >= 0 {
+		segs[i] = seg
+	} else {
+		segs = insertSegment(segs, seg)
+	}
+	return joinJpeg(segs, rest), nil
+}
+
+// flatten properties of all rdf:Description elements, values of arrays and structures are collected
+// under the top level property name; prefixes are keyed by namespace URI
+func xmpProperties(packet []byte) (values map[xml.Name][]string, prefixes map[string]string) {
+	values = map[xml.Name][]string{}
+	prefixes = map[string]string{"http://www.w3.org/XML/1998/namespace": "xml"}
+	var stack []xml.Name
+	var text strings.Builder
+	dec := xml.NewDecoder(bytes.NewReader(packet))
+	dec.Strict = false
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			break
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			for _, a := range t.Attr {
+				if a.Name.Space == "xmlns" {
+					prefixes[a.Value] = a.Name.Local
+				}
+			}
+			if t.Name.Space == nsRDF && t.Name.Local == "Description" && len(stack) == 0 {
+				for _, a := range t.Attr {
+					if a.Name.Space != "xmlns" && a.Name.Space != nsRDF && a.Name.Local != "xmlns" {
+						values[a.Name] = append(values[a.Name], a.Value)
+					}
+				}
+				continue
+			}
+			if t.Name.Space == nsRDF && len(stack) == 0 || t.Name.Space == "adobe:ns:meta/" {
+				continue // rdf:RDF and x:xmpmeta wrappers
+			}
+			stack = append(stack, t.Name)
+			text.Reset()
+		case xml.CharData:
+			text.Write(t)
+		case xml.EndElement:
+			if len(stack) == 0 {
+				continue
+			}
+			if v := strings.TrimSpace(text.String()); v != "" {
+				values[stack[0]] = append(values[stack[0]], v)
+			}
+			text.Reset()
+			stack = stack[:len(stack)-1]
+		}
+	}
+	return values, prefixes
+}
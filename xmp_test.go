@@ -0,0 +1,112 @@
+package main
+
+import (
+	"encoding/xml"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testRDF = `<x:xmpmeta xmlns:x="adobe:ns:meta/"><rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#">%s</rdf:RDF></x:xmpmeta>`
+
+// well-formed check of packet
+func xmlError(packet string) error {
+	dec := xml.NewDecoder(strings.NewReader(packet))
+	for {
+		if _, err := dec.Token(); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+func TestXMPSet(t *testing.T) {
+	city := xml.Name{Space: nsPhotoshop, Local: "City"}
+	tests := []struct {
+		name   string
+		packet string
+		prefix string              // prefix of xmp:Rating in result
+		kept   map[xml.Name]string // properties expected to be kept
+	}{
+		{"empty", "", "xmp:", nil},
+		{"no RDF", `<x:xmpmeta xmlns:x="adobe:ns:meta/"/>`, "xmp:", nil},
+		{"RDF without description", strings.Replace(testRDF, "%s", "", 1), "xmp:", nil},
+		{
+			"self-closing RDF",
+			`<x:xmpmeta xmlns:x="adobe:ns:meta/"><rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#"/></x:xmpmeta>`,
+			"xmp:",
+			nil,
+		},
+		{
+			"self-closing description",
+			strings.Replace(testRDF, "%s", `<rdf:Description rdf:about="" xmlns:photoshop="http://ns.adobe.com/photoshop/1.0/" photoshop:City="Oslo"/>`, 1),
+			"xmp:",
+			map[xml.Name]string{city: "Oslo"},
+		},
+		{
+			"attribute replaced",
+			strings.Replace(testRDF, "%s", `<rdf:Description rdf:about="" xmlns:xap="http://ns.adobe.com/xap/1.0/" xap:Rating="1" xmlns:photoshop="http://ns.adobe.com/photoshop/1.0/"><photoshop:City>Oslo</photoshop:City></rdf:Description>`, 1),
+			"xap:",
+			map[xml.Name]string{city: "Oslo"},
+		},
+		{
+			"element replaced",
+			strings.Replace(testRDF, "%s", `<rdf:Description rdf:about="" xmlns:xmp="http://ns.adobe.com/xap/1.0/" xmlns:dc="http://purl.org/dc/elements/1.1/">
+   <xmp:Rating>2</xmp:Rating>
+   <dc:subject><rdf:Bag><rdf:li>old</rdf:li></rdf:Bag></dc:subject>
+  </rdf:Description>`, 1),
+			"xmp:",
+			nil,
+		},
+		{
+			"two descriptions",
+			strings.Replace(testRDF, "%s", `<rdf:Description rdf:about="" xmlns:photoshop="http://ns.adobe.com/photoshop/1.0/" photoshop:City="Oslo"/><rdf:Description rdf:about=""/>`, 1),
+			"xmp:",
+			map[xml.Name]string{city: "Oslo"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			packet := xmpSet(tt.packet, xmpRating, "3")
+			packet = xmpSetArray(packet, xmpSubject, "Bag", []string{"sea", "a&b"})
+			packet = xmpSetArray(packet, xmpTitle, "Alt", []string{"Title"})
+			if err := xmlError(packet); err != nil {
+				t.Fatalf("%v in %s", err, packet)
+			}
+			values, _ := xmpProperties([]byte(packet))
+			want := map[xml.Name][]string{
+				{Space: nsXMP, Local: "Rating"}: {"3"},
+				{Space: nsDC, Local: "subject"}: {"sea", "a&b"},
+				{Space: nsDC, Local: "title"}:   {"Title"},
+			}
+			for n, v := range tt.kept {
+				want[n] = []string{v}
+			}
+			if !reflect.DeepEqual(values, want) {
+				t.Errorf("properties = %v, want %v in %s", values, want, packet)
+			}
+			if n := strings.Count(packet, tt.prefix+"Rating"); n != 1 {
+				t.Errorf("%d %sRating properties in %s", n, tt.prefix, packet)
+			}
+			// empty array removes property
+			packet = xmpSetArray(packet, xmpSubject, "Bag", nil)
+			if values, _ := xmpProperties([]byte(packet)); values[xml.Name{Space: nsDC, Local: "subject"}] != nil {
+				t.Errorf("subject not removed from %s", packet)
+			}
+		})
+	}
+}
+
+func TestXMLEscape(t *testing.T) {
+	packet := xmpSet("", xmpLabel, `"<Red> & 'Blue'"`)
+	values, _ := xmpProperties([]byte(packet))
+	if got := values[xml.Name{Space: nsXMP, Local: "Label"}]; !reflect.DeepEqual(got, []string{`"<Red> & 'Blue'"`}) {
+		t.Errorf("label = %q in %s", got, packet)
+	}
+	if !strings.Contains(packet, "&lt;Red&gt;") {
+		t.Errorf("label not escaped in %s", packet)
+	}
+}